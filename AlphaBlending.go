@@ -48,7 +48,7 @@ func (elem *AlphaBlending) SetMaster(source HubPort, zOrder int) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -75,6 +75,6 @@ func (elem *AlphaBlending) SetPortProperties(relativeX float64, relativeY float6
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
@@ -0,0 +1,88 @@
+package kurento
+
+import (
+	"errors"
+	"fmt"
+)
+
+type IChromaFilter interface {
+	SetBackground(uri string) error
+	UnsetBackground() error
+}
+
+// ChromaFilter interface. This type of `Filter` makes transparent a colour
+// range in the top layer, revealing another image behind.
+// The "window" option, a WindowParam, is required by "Create": it is the
+// region used to calibrate the background colour. "backgroundImage" is the
+// optional uri of the image shown in place of the colour.
+// This filter is part of the "chroma" module.
+type ChromaFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *ChromaFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Check that the calibration window is given
+func (elem *ChromaFilter) validateConstructorParams(params map[string]interface{}) error {
+	if params["window"] == nil {
+		return errors.New("kurento: ChromaFilter needs a \"window\" option")
+	}
+	return nil
+}
+
+// Implement moduleElement
+func (elem *ChromaFilter) kmsModule() string {
+	return "chroma"
+}
+
+// Sets the image to show on the detected chroma surface.
+func (elem *ChromaFilter) SetBackground(uri string) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "uri", uri)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "setBackground",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Clears the image used to be shown behind the chroma surface.
+func (elem *ChromaFilter) UnsetBackground() error {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "unsetBackground",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
@@ -0,0 +1,91 @@
+package kurento
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ICrowdDetectorFilter interface {
+}
+
+// Filter that detects people agglomeration in video streams.
+// The "rois" option, a list of RegionOfInterest, is required by "Create".
+// The filter raises "CrowdDetectorFluidity", "CrowdDetectorOccupancy" and
+// "CrowdDetectorDirection" events for each region.
+// This filter is part of the "crowddetector" module.
+type CrowdDetectorFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *CrowdDetectorFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Check that regions of interest are given
+func (elem *CrowdDetectorFilter) validateConstructorParams(params map[string]interface{}) error {
+	rois, ok := params["rois"].([]RegionOfInterest)
+	if params["rois"] == nil || (ok && len(rois) == 0) {
+		return errors.New("kurento: CrowdDetectorFilter needs a \"rois\" option")
+	}
+	for _, roi := range rois {
+		if len(roi.Points) < 3 {
+			return fmt.Errorf("kurento: region of interest %q needs at least 3 points", roi.Id)
+		}
+	}
+	return nil
+}
+
+// Implement moduleElement
+func (elem *CrowdDetectorFilter) kmsModule() string {
+	return "crowddetector"
+}
+
+// Event raised when a level of fluidity is detected in a region of interest
+type CrowdDetectorFluidity struct {
+	MediaEvent
+
+	// Percentage of fluidity in the ROI
+	FluidityPercentage float64
+
+	// Level of fluidity in the ROI
+	FluidityLevel int
+
+	// Id of the ROI
+	RoiID string
+}
+
+// Event raised when a level of occupancy is detected in a region of interest
+type CrowdDetectorOccupancy struct {
+	MediaEvent
+
+	// Percentage of occupancy in the ROI
+	OccupancyPercentage float64
+
+	// Level of occupancy in the ROI
+	OccupancyLevel int
+
+	// Id of the ROI
+	RoiID string
+}
+
+// Event raised when a movement direction is detected in a region of interest
+type CrowdDetectorDirection struct {
+	MediaEvent
+
+	// Direction angle of the detected movement in the ROI
+	DirectionAngle float64
+
+	// Id of the ROI
+	RoiID string
+}
@@ -46,6 +46,6 @@ func (elem *Dispatcher) Connect(source HubPort, sink HubPort) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
@@ -46,7 +46,7 @@ func (elem *DispatcherOneToMany) SetSource(source HubPort) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -63,6 +63,6 @@ func (elem *DispatcherOneToMany) RemoveSource() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
@@ -1 +1,7 @@
 package kurento
+
+// Fired when the end of the media is reached, e.g. by a `PlayerEndpoint`
+// that has played the whole file.
+type EndOfStream struct {
+	MediaEvent
+}
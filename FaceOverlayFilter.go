@@ -0,0 +1,76 @@
+package kurento
+
+import "fmt"
+
+type IFaceOverlayFilter interface {
+	UnsetOverlayedImage() error
+	SetOverlayedImage(uri string, offsetXPercent float64, offsetYPercent float64, widthPercent float64, heightPercent float64) error
+}
+
+// FaceOverlayFilter interface. This type of `Filter` detects faces in a video
+// feed. The face is then overlaid with an image.
+type FaceOverlayFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *FaceOverlayFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Clear the image to be shown over each detected face. Stops overlaying the
+// faces.
+func (elem *FaceOverlayFilter) UnsetOverlayedImage() error {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "unsetOverlayedImage",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Sets the image to use as overlay on the detected faces.
+// Offsets and sizes are relative to the detected face, e.g. an offsetYPercent
+// of -1.2 and a heightPercent of 1.6 put a hat over the head.
+func (elem *FaceOverlayFilter) SetOverlayedImage(uri string, offsetXPercent float64, offsetYPercent float64, widthPercent float64, heightPercent float64) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "uri", uri)
+	// all values are required, 0 is a valid offset
+	params["offsetXPercent"] = offsetXPercent
+	params["offsetYPercent"] = offsetYPercent
+	params["widthPercent"] = widthPercent
+	params["heightPercent"] = heightPercent
+
+	req["params"] = map[string]interface{}{
+		"operation":       "setOverlayedImage",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
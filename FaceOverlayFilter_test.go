@@ -0,0 +1,60 @@
+package kurento
+
+import "testing"
+
+func TestFaceOverlayFilter(t *testing.T) {
+	f := newFake(t)
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := &FaceOverlayFilter{}
+	if err := pipeline.Create(filter, nil); err != nil {
+		t.Fatal(err)
+	}
+	req := f.last()
+	if req["method"] != "create" || paramsOf(req)["type"] != "FaceOverlayFilter" {
+		t.Fatalf("unexpected create request %v", req)
+	}
+	constructor, _ := paramsOf(req)["constructorParams"].(map[string]interface{})
+	if len(constructor) != 1 || constructor["mediaPipeline"] != pipeline.Id {
+		t.Fatalf("unexpected constructor params %v", constructor)
+	}
+
+	if err := filter.SetOverlayedImage("http://files/hat.png", 0, -1.2, 1.6, 1.6); err != nil {
+		t.Fatal(err)
+	}
+	req = f.last()
+	if req["method"] != "invoke" || paramsOf(req)["operation"] != "setOverlayedImage" || paramsOf(req)["object"] != filter.Id {
+		t.Fatalf("unexpected request %v", req)
+	}
+	want := map[string]interface{}{
+		"uri":            "http://files/hat.png",
+		"offsetXPercent": 0.0,
+		"offsetYPercent": -1.2,
+		"widthPercent":   1.6,
+		"heightPercent":  1.6,
+	}
+	params := operationParamsOf(req)
+	if len(params) != len(want) {
+		t.Fatalf("operation params %v, want %v", params, want)
+	}
+	for k, v := range want {
+		if got, ok := params[k]; !ok || got != v {
+			t.Errorf("%s = %v, want %v", k, got, v)
+		}
+	}
+
+	if err := filter.UnsetOverlayedImage(); err != nil {
+		t.Fatal(err)
+	}
+	req = f.last()
+	if paramsOf(req)["operation"] != "unsetOverlayedImage" || paramsOf(req)["object"] != filter.Id {
+		t.Fatalf("unexpected request %v", req)
+	}
+	if _, ok := paramsOf(req)["operationParams"]; ok {
+		t.Fatalf("unsetOverlayedImage has no params: %v", req)
+	}
+}
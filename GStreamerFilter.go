@@ -0,0 +1,174 @@
+package kurento
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type IGStreamerFilter interface {
+	SetElementProperty(propertyName string, propertyValue string) error
+}
+
+// This is a generic filter interface, that creates GStreamer filters in the
+// media server.
+type GStreamerFilter struct {
+	Filter
+
+	// GStreamer command, in gst-launch format. Used by "Create" if "command"
+	// option is not given.
+	Command string
+
+	// Filter type to create, used by "Create" if "filterType" option is not
+	// given. Default value: AUTODETECT
+	FilterType FilterType
+}
+
+// Return contructor params to be called by "Create".
+func (elem *GStreamerFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+		"command":       elem.Command,
+		"filterType":    fmt.Sprintf("%s", FILTERTYPE_AUTODETECT),
+	}
+	setIfNotEmpty(ret, "filterType", elem.FilterType)
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Check the command before it is sent to the server
+func (elem *GStreamerFilter) validateConstructorParams(params map[string]interface{}) error {
+	command := fmt.Sprintf("%v", params["command"])
+	_, err := ParseGStreamerCommand(command)
+	return err
+}
+
+// Provide a value to one of the GStreamer element's properties.
+func (elem *GStreamerFilter) SetElementProperty(propertyName string, propertyValue string) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "propertyName", propertyName)
+	// empty value is valid
+	params["propertyValue"] = propertyValue
+
+	req["params"] = map[string]interface{}{
+		"operation":       "setElementProperty",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// GStreamerElement is an element of a gst-launch command.
+type GStreamerElement struct {
+	// Element factory, e.g. "videoflip", or caps, e.g. "video/x-raw,width=320"
+	Name string
+
+	// Properties set on the element, quotes are removed from values
+	Properties map[string]string
+}
+
+// ParseGStreamerCommand splits a gst-launch command into elements. It only
+// detects obvious syntax errors, such as unbalanced quotes or empty elements,
+// the media server may still reject the command.
+func ParseGStreamerCommand(command string) ([]GStreamerElement, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("gstreamer command is empty")
+	}
+
+	parts, err := splitGStreamerCommand(command, '!')
+	if err != nil {
+		return nil, err
+	}
+
+	elements := []GStreamerElement{}
+	for i, part := range parts {
+		words, err := splitGStreamerCommand(part, ' ')
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("gstreamer command: element %d has no name", i+1)
+		}
+
+		e := GStreamerElement{
+			Name:       words[0],
+			Properties: make(map[string]string),
+		}
+		if !unicode.IsLetter([]rune(e.Name)[0]) {
+			return nil, fmt.Errorf("gstreamer command: element %d has an invalid name %q", i+1, e.Name)
+		}
+		for _, w := range words[1:] {
+			kv := strings.SplitN(w, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("gstreamer command: invalid property %q of %s", w, e.Name)
+			}
+			e.Properties[kv[0]] = unquoteGStreamerValue(kv[1])
+		}
+		elements = append(elements, e)
+	}
+	return elements, nil
+}
+
+// Remove the quotes around a property value, escaped quotes are kept
+func unquoteGStreamerValue(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// Split s on sep characters that are not quoted. Spaces are trimmed, and
+// consecutive spaces are merged when sep is a space.
+func splitGStreamerCommand(s string, sep rune) ([]string, error) {
+	ret := []string{}
+	var quote rune
+	escaped := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == sep || (sep == ' ' && unicode.IsSpace(r)):
+			ret = append(ret, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("gstreamer command: unbalanced %c quote", quote)
+	}
+	ret = append(ret, strings.TrimSpace(s[start:]))
+
+	if sep == ' ' {
+		words := ret[:0]
+		for _, w := range ret {
+			if w != "" {
+				words = append(words, w)
+			}
+		}
+		return words, nil
+	}
+	return ret, nil
+}
@@ -0,0 +1,107 @@
+package kurento
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGStreamerCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []GStreamerElement
+	}{
+		{
+			"videoflip method=horizontal-flip",
+			[]GStreamerElement{
+				{"videoflip", map[string]string{"method": "horizontal-flip"}},
+			},
+		},
+		{
+			`capsfilter caps="video/x-raw, width=(int)320, height=(int)240" ! videobalance saturation=0.0`,
+			[]GStreamerElement{
+				{"capsfilter", map[string]string{"caps": "video/x-raw, width=(int)320, height=(int)240"}},
+				{"videobalance", map[string]string{"saturation": "0.0"}},
+			},
+		},
+		{
+			"video/x-raw,width=320,framerate=15/1 ! videoscale",
+			[]GStreamerElement{
+				{"video/x-raw,width=320,framerate=15/1", map[string]string{}},
+				{"videoscale", map[string]string{}},
+			},
+		},
+		{
+			`textoverlay text='a ! b' font-desc="Sans 24"   valignment=top`,
+			[]GStreamerElement{
+				{"textoverlay", map[string]string{"text": "a ! b", "font-desc": "Sans 24", "valignment": "top"}},
+			},
+		},
+		{
+			`textoverlay text="say \"hi\""`,
+			[]GStreamerElement{
+				{"textoverlay", map[string]string{"text": `say \"hi\"`}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseGStreamerCommand(tt.command)
+		if err != nil {
+			t.Errorf("ParseGStreamerCommand(%q): %v", tt.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseGStreamerCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestParseGStreamerCommandErrors(t *testing.T) {
+	for _, command := range []string{
+		"",
+		"   ",
+		"videoflip ! ! videoscale",
+		"! videoflip",
+		"videoflip !",
+		`textoverlay text="hello`,
+		`textoverlay text='hello`,
+		"videoflip =horizontal-flip",
+		"videoflip horizontal-flip",
+		"3videoflip",
+	} {
+		if _, err := ParseGStreamerCommand(command); err == nil {
+			t.Errorf("ParseGStreamerCommand(%q) should fail", command)
+		}
+	}
+}
+
+func TestGStreamerFilterCreate(t *testing.T) {
+	f := newFake(t)
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid commands are not sent
+	n := len(f.reqs)
+	if err := pipeline.Create(&GStreamerFilter{Command: "videoflip ! ! videoscale"}, nil); err == nil {
+		t.Error("Create should fail with an invalid command")
+	}
+	if len(f.reqs) != n {
+		t.Error("an invalid command was sent to the server")
+	}
+
+	filter := &GStreamerFilter{Command: "videoflip method=horizontal-flip", FilterType: FILTERTYPE_VIDEO}
+	if err := pipeline.Create(filter, nil); err != nil {
+		t.Fatal(err)
+	}
+	params, _ := paramsOf(f.last())["constructorParams"].(map[string]interface{})
+	want := map[string]interface{}{
+		"mediaPipeline": pipeline.Id,
+		"command":       "videoflip method=horizontal-flip",
+		"filterType":    "VIDEO",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("constructorParams = %v, want %v", params, want)
+	}
+}
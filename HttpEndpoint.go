@@ -9,6 +9,7 @@ type IHttpGetEndpoint interface {
 // using HTML5 pseudo-streaming mechanism.
 // This type of endpoint provide unidirectional communications. Its `MediaSink`
 // is associated with the HTTP GET method
+// Only available in KMS 5.
 type HttpGetEndpoint struct {
 	HttpEndpoint
 }
@@ -19,8 +20,8 @@ func (elem *HttpGetEndpoint) getConstructorParams(from IMediaObject, options map
 	// Create basic constructor params
 	ret := map[string]interface{}{
 		"mediaPipeline":        fmt.Sprintf("%s", from),
-		"terminateOnEOS":       fmt.Sprintf("%s", from),
-		"mediaProfile":         fmt.Sprintf("%s", from),
+		"terminateOnEOS":       false,
+		"mediaProfile":         MEDIAPROFILESPECTYPE_WEBM,
 		"disconnectionTimeout": 2,
 	}
 
@@ -49,7 +50,7 @@ func (elem *HttpPostEndpoint) getConstructorParams(from IMediaObject, options ma
 	ret := map[string]interface{}{
 		"mediaPipeline":        fmt.Sprintf("%s", from),
 		"disconnectionTimeout": 2,
-		"useEncodedMedia":      fmt.Sprintf("%s", from),
+		"useEncodedMedia":      false,
 	}
 
 	// then merge options
@@ -91,6 +92,6 @@ func (elem *HttpEndpoint) GetUrl() (string, error) {
 
 	// // The url as a String
 
-	return response.Result["value"], response.Error
+	return response.Result["value"], response.Err()
 
 }
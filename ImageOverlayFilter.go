@@ -0,0 +1,163 @@
+package kurento
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type IImageOverlayFilter interface {
+	RemoveImage(id string) error
+	AddImage(id string, uri string, offsetXPercent float64, offsetYPercent float64, widthPercent float64, heightPercent float64, keepAspectRatio bool, center bool) error
+}
+
+// ImageOverlayFilter interface. This type of `Filter` draws images over a
+// video feed, e.g. watermarks or badges.
+type ImageOverlayFilter struct {
+	Filter
+
+	// images added with AddImage, by id
+	mu     sync.Mutex
+	images map[string]OverlayImage
+}
+
+// An image drawn by an `ImageOverlayFilter`
+type OverlayImage struct {
+	// Image ID
+	Id string
+
+	// URI where the image is located
+	Uri string
+
+	// Position of the image, in percent of the video width and height
+	OffsetXPercent float64
+	OffsetYPercent float64
+
+	// Size of the image, in percent of the video width and height
+	WidthPercent  float64
+	HeightPercent float64
+
+	// Keep the aspect ratio of the original image
+	KeepAspectRatio bool
+
+	// If the image doesn't fit in the dimensions, the image will be center
+	// into the region defined by height and width
+	Center bool
+}
+
+// Return contructor params to be called by "Create".
+func (elem *ImageOverlayFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Remove the image with the given ID.
+func (elem *ImageOverlayFilter) RemoveImage(id string) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "id", id)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "removeImage",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	if response.Error == nil {
+		elem.mu.Lock()
+		delete(elem.images, id)
+		elem.mu.Unlock()
+	}
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Add an image to be used as overlay.
+func (elem *ImageOverlayFilter) AddImage(id string, uri string, offsetXPercent float64, offsetYPercent float64, widthPercent float64, heightPercent float64, keepAspectRatio bool, center bool) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "id", id)
+	setIfNotEmpty(params, "uri", uri)
+	// all values are required, 0 and false are valid
+	params["offsetXPercent"] = offsetXPercent
+	params["offsetYPercent"] = offsetYPercent
+	params["widthPercent"] = widthPercent
+	params["heightPercent"] = heightPercent
+	params["keepAspectRatio"] = keepAspectRatio
+	params["center"] = center
+
+	req["params"] = map[string]interface{}{
+		"operation":       "addImage",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	if response.Error == nil {
+		elem.mu.Lock()
+		if elem.images == nil {
+			elem.images = make(map[string]OverlayImage)
+		}
+		elem.images[id] = OverlayImage{id, uri, offsetXPercent, offsetYPercent, widthPercent, heightPercent, keepAspectRatio, center}
+		elem.mu.Unlock()
+	}
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Images returns the images added with AddImage and not removed, sorted by id.
+func (elem *ImageOverlayFilter) Images() []OverlayImage {
+	elem.mu.Lock()
+	defer elem.mu.Unlock()
+
+	ret := []OverlayImage{}
+	for _, img := range elem.images {
+		ret = append(ret, img)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Id < ret[j].Id
+	})
+	return ret
+}
+
+// Image returns the image with the given id, false if there is none.
+func (elem *ImageOverlayFilter) Image(id string) (OverlayImage, bool) {
+	elem.mu.Lock()
+	defer elem.mu.Unlock()
+
+	img, ok := elem.images[id]
+	return img, ok
+}
+
+// UpdateImage replaces the image that has the same id, e.g. to move it. The
+// image is added if it doesn't exist.
+func (elem *ImageOverlayFilter) UpdateImage(img OverlayImage) error {
+	if _, ok := elem.Image(img.Id); ok {
+		if err := elem.RemoveImage(img.Id); err != nil {
+			return err
+		}
+	}
+	return elem.AddImage(img.Id, img.Uri, img.OffsetXPercent, img.OffsetYPercent, img.WidthPercent, img.HeightPercent, img.KeepAspectRatio, img.Center)
+}
@@ -0,0 +1,242 @@
+package kurento
+
+type IMediaPad interface {
+	GetMediaType() (MediaType, error)
+	GetMediaDescription() (string, error)
+}
+
+// A `MediaPad` is an element's interface with the outside world. The data
+// streams flow from the `MediaSource` pad to another element's `MediaSink`
+// pad. Pads are returned by GetMediaSrcs and GetMediaSinks of their
+// `MediaElement`, that is their parent. Only available in KMS 5.
+type MediaPad struct {
+	MediaObject
+
+	// The `MediaElement` that contains this pad
+	MediaElement IMediaElement
+
+	// The type of media that the pad accepts. Empty until GetMediaType is
+	// called if the pad has not been retrieved for a given media type.
+	MediaType MediaType
+
+	// The description of the pad, see GetMediaDescription
+	MediaDescription string
+}
+
+// Return contructor params to be called by "Create".
+func (elem *MediaPad) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+	return options
+
+}
+
+// Get the type of media that the pad accepts.
+// Returns:
+// // The type of media
+func (elem *MediaPad) GetMediaType() (MediaType, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getMediaType",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The type of media
+
+	if err := response.Err(); err != nil {
+		return "", err
+	}
+	elem.MediaType = MediaType(response.Result["value"])
+	return elem.MediaType, nil
+
+}
+
+// Get the description of the pad, that can be given as media description to
+// `MediaElement.Connect`.
+// Returns:
+// // The description of the pad
+func (elem *MediaPad) GetMediaDescription() (string, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getMediaDescription",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The description of the pad
+
+	if err := response.Err(); err != nil {
+		return "", err
+	}
+	elem.MediaDescription = response.Result["value"]
+	return elem.MediaDescription, nil
+
+}
+
+type IMediaSource interface {
+	Connect(sink *MediaSink) error
+	Disconnect(sink *MediaSink) error
+	GetConnectedSinks() ([]*MediaSink, error)
+}
+
+// Special type of pad, used by a media element to generate a media stream.
+type MediaSource struct {
+	MediaPad
+}
+
+// Connects the current source with a `MediaSink`
+func (elem *MediaSource) Connect(sink *MediaSink) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "sink", sink)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "connect",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Disconnects the current source from a `MediaSink`
+func (elem *MediaSource) Disconnect(sink *MediaSink) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "sink", sink)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "disconnect",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Gets all the `MediaSinks<MediaSink>` to which this source is connected. The
+// sinks have the media type of the source, and no `MediaElement` set as the
+// server only gives their id.
+// Returns:
+// // the list of sinks that the source is connected to
+func (elem *MediaSource) GetConnectedSinks() ([]*MediaSink, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getConnectedSinks",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // the list of sinks that the source is connected to
+
+	ids := []string{}
+	if err := response.decodeValue(&ids); err != nil {
+		return nil, err
+	}
+	ret := []*MediaSink{}
+	for _, id := range ids {
+		sink := &MediaSink{}
+		sink.MediaType = elem.MediaType
+		sink.setConnection(elem.connection)
+		sink.setId(id)
+		ret = append(ret, sink)
+	}
+	return ret, nil
+
+}
+
+type IMediaSink interface {
+	Disconnect(src *MediaSource) error
+	GetConnectedSrc() (*MediaSource, error)
+}
+
+// Special type of pad, used by a `MediaElement` to receive a media stream.
+type MediaSink struct {
+	MediaPad
+}
+
+// Disconnects the current sink from the referred `MediaSource`
+func (elem *MediaSink) Disconnect(src *MediaSource) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "src", src)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "disconnect",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Gets the `MediaSource` that is connected to this sink, nil if there is none.
+// Returns:
+// // The source connected to this sink
+func (elem *MediaSink) GetConnectedSrc() (*MediaSource, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getConnectedSrc",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The source connected to this sink
+
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	id := response.Result["value"]
+	if id == "" {
+		return nil, nil
+	}
+	src := &MediaSource{}
+	src.MediaType = elem.MediaType
+	src.setConnection(elem.connection)
+	src.setId(id)
+	return src, nil
+
+}
+
+// Set the pad fields from the element that returned it
+func (elem *MediaPad) init(parent *MediaElement, id string, mediaType MediaType, description string) {
+	elem.setConnection(parent.connection)
+	elem.setId(id)
+	elem.setParent(parent)
+	elem.MediaPipeline = parent.MediaPipeline
+	elem.MediaElement = parent
+	elem.MediaType = mediaType
+	elem.MediaDescription = description
+}
@@ -49,7 +49,7 @@ func (elem *Mixer) Connect(media MediaType, source HubPort, sink HubPort) error
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -74,6 +74,6 @@ func (elem *Mixer) Disconnect(media MediaType, source HubPort, sink HubPort) err
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
@@ -0,0 +1,66 @@
+package kurento
+
+import "fmt"
+
+type IPlateDetectorFilter interface {
+	SetPlateWidthPercentage(plateWidthPercentage float64) error
+}
+
+// PlateDetectorFilter interface. This type of `Filter` detects license plates
+// in a video feed and raises "PlateDetected" events.
+// This filter is part of the "platedetector" module.
+type PlateDetectorFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *PlateDetectorFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Implement moduleElement
+func (elem *PlateDetectorFilter) kmsModule() string {
+	return "platedetector"
+}
+
+// Configures the average width of the license plates in the image represented
+// as an image percentage.
+func (elem *PlateDetectorFilter) SetPlateWidthPercentage(plateWidthPercentage float64) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "plateWidthPercentage", plateWidthPercentage)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "setPlateWidthPercentage",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Event raised by a `PlateDetectorFilter` when a license plate is found in the
+// data streamed.
+type PlateDetected struct {
+	MediaEvent
+
+	// Plate identifier that was detected
+	Plate string
+}
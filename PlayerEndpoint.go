@@ -4,6 +4,10 @@ import "fmt"
 
 type IPlayerEndpoint interface {
 	Play() error
+	GetPosition() (int64, error)
+	SetPosition(position int64) error
+	GetVideoInfo() (VideoInfo, error)
+	OnEndOfStream(handler func(*EndOfStream)) (string, error)
 }
 
 // Retrieves content from seekable sources in reliable
@@ -12,6 +16,11 @@ type IPlayerEndpoint interface {
 // contains one `MediaSource` for each media type detected.
 type PlayerEndpoint struct {
 	UriEndpoint
+
+	// Size of the buffer of network sources, in milliseconds, used by
+	// "Create" if "networkCache" option is not given. 0 keeps the server
+	// default. Only available in KMS 6.
+	NetworkCache int
 }
 
 // Return contructor params to be called by "Create".
@@ -21,8 +30,9 @@ func (elem *PlayerEndpoint) getConstructorParams(from IMediaObject, options map[
 	ret := map[string]interface{}{
 		"mediaPipeline":   fmt.Sprintf("%s", from),
 		"uri":             "",
-		"useEncodedMedia": fmt.Sprintf("%s", from),
+		"useEncodedMedia": false,
 	}
+	setIfNotEmpty(ret, "networkCache", elem.NetworkCache)
 
 	// then merge options
 	mergeOptions(ret, options)
@@ -44,6 +54,85 @@ func (elem *PlayerEndpoint) Play() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
+
+}
+
+// Get the current position in the media, in milliseconds.
+// Returns:
+// // The position in milliseconds
+func (elem *PlayerEndpoint) GetPosition() (int64, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getPosition",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The position in milliseconds
+
+	var position int64
+	err := response.decodeValue(&position)
+	return position, err
+
+}
+
+// Seek to the given position in the media, in milliseconds. The media must be
+// seekable, see GetVideoInfo.
+func (elem *PlayerEndpoint) SetPosition(position int64) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	// 0 is a valid position, the start of the media
+	params["position"] = position
+
+	req["params"] = map[string]interface{}{
+		"operation":       "setPosition",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Get information about the played media, such as its duration and the
+// range where seek is possible.
+// Returns:
+// // The video info
+func (elem *PlayerEndpoint) GetVideoInfo() (VideoInfo, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getVideoInfo",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The video info
+
+	info := VideoInfo{}
+	err := response.decodeValue(&info)
+	return info, err
+
+}
 
+// Subscribe handler to "EndOfStream" events, raised when the whole media has
+// been played. The returned id can be given to Unsubscribe.
+func (elem *PlayerEndpoint) OnEndOfStream(handler func(*EndOfStream)) (string, error) {
+	return elem.Subscribe("EndOfStream", func(ev Event) {
+		if e, ok := ev.(*EndOfStream); ok {
+			handler(e)
+		}
+	})
 }
@@ -0,0 +1,147 @@
+package kurento
+
+import (
+	"errors"
+	"fmt"
+)
+
+type IPointerDetectorFilter interface {
+	AddWindow(window PointerDetectorWindowMediaParam) error
+	ClearWindows() error
+	TrackColorFromCalibrationRegion() error
+	RemoveWindow(windowId string) error
+}
+
+// This type of `Filter` detects a pointer of a given colour in a video feed,
+// and raises "WindowIn" and "WindowOut" events when it enters or leaves the
+// configured windows.
+// The "calibrationRegion" option, a WindowParam, is required by "Create".
+// "windows" is an optional list of PointerDetectorWindowMediaParam.
+// This filter is part of the "pointerdetector" module.
+type PointerDetectorFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *PointerDetectorFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Check that the calibration region is given
+func (elem *PointerDetectorFilter) validateConstructorParams(params map[string]interface{}) error {
+	if params["calibrationRegion"] == nil {
+		return errors.New("kurento: PointerDetectorFilter needs a \"calibrationRegion\" option")
+	}
+	return nil
+}
+
+// Implement moduleElement
+func (elem *PointerDetectorFilter) kmsModule() string {
+	return "pointerdetector"
+}
+
+// Adds a pointer detector window. When a pointer enters or exits this window,
+// the filter will raise an event indicating so.
+func (elem *PointerDetectorFilter) AddWindow(window PointerDetectorWindowMediaParam) error {
+	req := elem.getInvokeRequest()
+
+	params := map[string]interface{}{
+		"window": window,
+	}
+
+	req["params"] = map[string]interface{}{
+		"operation":       "addWindow",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Removes all pointer detector windows
+func (elem *PointerDetectorFilter) ClearWindows() error {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "clearWindows",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// This method allows to calibrate the tracking color.
+// The new tracking color will be the color of the object in the colorCalibrationRegion.
+func (elem *PointerDetectorFilter) TrackColorFromCalibrationRegion() error {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "trackColorFromCalibrationRegion",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Removes a pointer detector window
+func (elem *PointerDetectorFilter) RemoveWindow(windowId string) error {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "windowId", windowId)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "removeWindow",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Event generated when an object enters a window.
+type WindowIn struct {
+	MediaEvent
+
+	// Opaque String indicating the id of the window entered
+	WindowId string
+}
+
+// Event generated when an object exits a window.
+type WindowOut struct {
+	MediaEvent
+
+	// Opaque String indicating the id of the window entered
+	WindowId string
+}
@@ -1,9 +1,13 @@
 package kurento
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type IRecorderEndpoint interface {
 	Record() error
+	StopAndWait(ctx context.Context) error
 }
 
 // Provides function to store contents in reliable mode (doesn't discard data). It
@@ -19,8 +23,8 @@ func (elem *RecorderEndpoint) getConstructorParams(from IMediaObject, options ma
 	ret := map[string]interface{}{
 		"mediaPipeline":     fmt.Sprintf("%s", from),
 		"uri":               "",
-		"mediaProfile":      fmt.Sprintf("%s", from),
-		"stopOnEndOfStream": fmt.Sprintf("%s", from),
+		"mediaProfile":      MEDIAPROFILESPECTYPE_WEBM,
+		"stopOnEndOfStream": false,
 	}
 
 	// then merge options
@@ -43,6 +47,50 @@ func (elem *RecorderEndpoint) Record() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
+
+// Stops recording and waits for the "Stopped" event, that is raised once the
+// media server has written the file. It returns ctx.Err() if ctx is done
+// before.
+func (elem *RecorderEndpoint) StopAndWait(ctx context.Context) error {
+	stopped := make(chan struct{}, 1)
+	id, err := elem.Subscribe("Stopped", func(Event) {
+		select {
+		case stopped <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer elem.Unsubscribe(id)
+
+	if err := elem.Stop(); err != nil {
+		return err
+	}
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Fired when the recording effectively starts, i.e. media is received by the
+// recorder and "Record" has been called.
+type Recording struct {
+	MediaEvent
+}
+
+// Fired when the recorder goes to pause state
+type Paused struct {
+	MediaEvent
+}
+
+// Fired when the recorder has been stopped and the file is complete
+type Stopped struct {
+	MediaEvent
+}
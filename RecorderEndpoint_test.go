@@ -0,0 +1,53 @@
+package kurento
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecorderEndpointStopAndWait(t *testing.T) {
+	f := newFake(t)
+	stopped := true
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] == "stop" && stopped {
+			// the event is sent after the response
+			object, _ := paramsOf(req)["object"].(string)
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				f.event(object, "Stopped", map[string]interface{}{})
+			}()
+		}
+		return nil
+	}
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	recorder := &RecorderEndpoint{}
+	if err := pipeline.Create(recorder, map[string]interface{}{"uri": "file:///tmp/a.webm"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := recorder.StopAndWait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if method := f.last()["method"]; method != "unsubscribe" {
+		t.Errorf("last method = %v, want unsubscribe", method)
+	}
+
+	// the server never raises Stopped
+	f.mu.Lock()
+	stopped = false
+	f.mu.Unlock()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := recorder.StopAndWait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("StopAndWait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if method := f.last()["method"]; method != "unsubscribe" {
+		t.Errorf("last method = %v, want unsubscribe", method)
+	}
+}
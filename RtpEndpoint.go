@@ -10,6 +10,10 @@ type IRtpEndpoint interface {
 // and source `MediaPad` for audio and video.
 type RtpEndpoint struct {
 	SdpEndpoint
+
+	// SRTP configuration, used by "Create" if "crypto" option is not given.
+	// Media is not encrypted if nil.
+	Crypto *SDES
 }
 
 // Return contructor params to be called by "Create".
@@ -19,6 +23,9 @@ func (elem *RtpEndpoint) getConstructorParams(from IMediaObject, options map[str
 	ret := map[string]interface{}{
 		"mediaPipeline": fmt.Sprintf("%s", from),
 	}
+	if elem.Crypto != nil {
+		ret["crypto"] = *elem.Crypto
+	}
 
 	// then merge options
 	mergeOptions(ret, options)
@@ -26,3 +33,14 @@ func (elem *RtpEndpoint) getConstructorParams(from IMediaObject, options map[str
 	return ret
 
 }
+
+// Check the SRTP configuration
+func (elem *RtpEndpoint) validateConstructorParams(params map[string]interface{}) error {
+	switch crypto := params["crypto"].(type) {
+	case SDES:
+		return crypto.Validate()
+	case *SDES:
+		return crypto.Validate()
+	}
+	return nil
+}
@@ -1,10 +1,21 @@
 package kurento
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net"
+)
 
 type IWebRtcEndpoint interface {
 	GatherCandidates() error
 	AddIceCandidate(candidate IceCandidate) error
+	CreateDataChannel(label string, ordered bool, maxPacketLifeTime int, maxRetransmits int, protocol string) error
+	CloseDataChannel(channelId int) error
+	SetStunServerAddress(stunServerAddress string) error
+	SetStunServerPort(stunServerPort int) error
+	SetTurnUrl(turnUrl string) error
+	SetTurnConfig(config TurnConfig) error
+	SetExternalAddress(externalAddress string) error
 }
 
 // WebRtcEndpoint interface. This type of "Endpoint" offers media streaming using
@@ -17,6 +28,21 @@ type WebRtcEndpoint struct {
 
 	// Port of the STUN server
 	StunServerPort int
+
+	// TURN server URL, see TurnConfig
+	TurnUrl string
+
+	// External IP address of the media server, used in place of the local
+	// addresses in ICE candidates
+	ExternalAddress string
+
+	// Activate data channels support, used by "Create" if "useDataChannels"
+	// option is not given
+	UseDataChannels bool
+
+	// Key type of the DTLS certificate, used by "Create" if
+	// "certificateKeyType" option is not given. Only available in KMS 6.
+	CertificateKeyType CertificateKeyType
 }
 
 // Return contructor params to be called by "Create".
@@ -26,6 +52,8 @@ func (elem *WebRtcEndpoint) getConstructorParams(from IMediaObject, options map[
 	ret := map[string]interface{}{
 		"mediaPipeline": fmt.Sprintf("%s", from),
 	}
+	setIfNotEmpty(ret, "useDataChannels", elem.UseDataChannels)
+	setIfNotEmpty(ret, "certificateKeyType", elem.CertificateKeyType)
 
 	// then merge options
 	mergeOptions(ret, options)
@@ -48,7 +76,7 @@ func (elem *WebRtcEndpoint) GatherCandidates() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -70,6 +98,173 @@ func (elem *WebRtcEndpoint) AddIceCandidate(candidate IceCandidate) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
+
+}
+
+// Create a new data channel, if data channels are supported. The channel id
+// is given by the "DataChannelOpened" event.
+// maxPacketLifeTime and maxRetransmits limit the retransmissions in unreliable
+// mode, -1 means no limit. Only one of them can be set.
+func (elem *WebRtcEndpoint) CreateDataChannel(label string, ordered bool, maxPacketLifeTime int, maxRetransmits int, protocol string) error {
+	if maxPacketLifeTime >= 0 && maxRetransmits >= 0 {
+		return errors.New("kurento: maxPacketLifeTime and maxRetransmits can't be both set")
+	}
+
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "label", label)
+	// false and -1 are valid values
+	params["ordered"] = ordered
+	params["maxPacketLifeTime"] = maxPacketLifeTime
+	params["maxRetransmits"] = maxRetransmits
+	setIfNotEmpty(params, "protocol", protocol)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "createDataChannel",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+
+}
+
+// Closes an open data channel
+func (elem *WebRtcEndpoint) CloseDataChannel(channelId int) error {
+	req := elem.getInvokeRequest()
+
+	params := map[string]interface{}{
+		// 0 is a valid id
+		"channelId": channelId,
+	}
+
+	req["params"] = map[string]interface{}{
+		"operation":       "closeDataChannel",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
 
 }
+
+// Event fired when a new data channel is created.
+type DataChannelOpened struct {
+	MediaEvent
+
+	// The channel identifier
+	ChannelId int
+}
+
+// Event fired when a data channel is closed.
+type DataChannelClosed struct {
+	MediaEvent
+
+	// The channel identifier
+	ChannelId int
+}
+
+// Event fired when a new local ICE candidate is found, to be sent to the
+// remote peer. KMS 5 names it "OnIceCandidate".
+type IceCandidateFound struct {
+	MediaEvent
+
+	// The local candidate
+	Candidate IceCandidate
+}
+
+// Event fired when all local ICE candidates have been found. KMS 5 names it
+// "OnIceGatheringDone".
+type IceGatheringDone struct {
+	MediaEvent
+}
+
+// Event fired when the state of an ICE component changes. Only available in
+// KMS 6.
+type IceComponentStateChange struct {
+	MediaEvent
+
+	StreamId    int
+	ComponentId int
+	State       IceComponentState
+}
+
+// Event fired when a new pair of ICE candidates is selected. Only available in
+// KMS 6.
+type NewCandidatePairSelected struct {
+	MediaEvent
+
+	CandidatePair IceCandidatePair
+}
+
+// Set the address of the STUN server, only IP addresses are supported.
+func (elem *WebRtcEndpoint) SetStunServerAddress(stunServerAddress string) error {
+	if net.ParseIP(stunServerAddress) == nil {
+		return fmt.Errorf("kurento: STUN server address %q is not an IP address", stunServerAddress)
+	}
+
+	if err := elem.setProperty("setStunServerAddress", "stunServerAddress", stunServerAddress); err != nil {
+		return err
+	}
+	elem.StunServerAddress = stunServerAddress
+	return nil
+}
+
+// Set the port of the STUN server
+func (elem *WebRtcEndpoint) SetStunServerPort(stunServerPort int) error {
+	if stunServerPort <= 0 || stunServerPort > 65535 {
+		return fmt.Errorf("kurento: invalid STUN server port %d", stunServerPort)
+	}
+
+	if err := elem.setProperty("setStunServerPort", "stunServerPort", stunServerPort); err != nil {
+		return err
+	}
+	elem.StunServerPort = stunServerPort
+	return nil
+}
+
+// Set the TURN server URL, with the format
+// "user:password@address:port(?transport=[udp|tcp|tls])". Address must be
+// an IP address. Use TurnConfig to build and check it.
+func (elem *WebRtcEndpoint) SetTurnUrl(turnUrl string) error {
+	if err := elem.setProperty("setTurnUrl", "turnUrl", turnUrl); err != nil {
+		return err
+	}
+	elem.TurnUrl = turnUrl
+	return nil
+}
+
+// Set the TURN server from a TurnConfig, after it is checked.
+func (elem *WebRtcEndpoint) SetTurnConfig(config TurnConfig) error {
+	url, err := config.Url()
+	if err != nil {
+		return err
+	}
+	return elem.SetTurnUrl(url)
+}
+
+// Set the external IPv4 or IPv6 address of the media server, used in the
+// ICE candidates in place of the local addresses. This is useful when the
+// server is behind a NAT.
+func (elem *WebRtcEndpoint) SetExternalAddress(externalAddress string) error {
+	if net.ParseIP(externalAddress) == nil {
+		return fmt.Errorf("kurento: external address %q is not an IP address", externalAddress)
+	}
+
+	if err := elem.setProperty("setExternalAddress", "externalAddress", externalAddress); err != nil {
+		return err
+	}
+	elem.ExternalAddress = externalAddress
+	return nil
+}
@@ -0,0 +1,80 @@
+package kurento
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type IZBarFilter interface {
+	OnCodeFound(window time.Duration, handler func(*CodeFound)) (string, error)
+}
+
+// This `Filter` detects QR and bar codes in a video feed. When a code is found,
+// the filter raises a "CodeFound" event.
+type ZBarFilter struct {
+	Filter
+}
+
+// Return contructor params to be called by "Create".
+func (elem *ZBarFilter) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline": fmt.Sprintf("%s", from),
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Subscribe handler to "CodeFound" events. The filter raises the event for
+// each frame where the code is visible, so a code is given to handler only
+// once per window, or each time if window is 0. The returned id can be given
+// to Unsubscribe.
+func (elem *ZBarFilter) OnCodeFound(window time.Duration, handler func(*CodeFound)) (string, error) {
+	var mu sync.Mutex
+	reported := make(map[CodeFound]time.Time)
+
+	return elem.Subscribe("CodeFound", func(ev Event) {
+		e, ok := ev.(*CodeFound)
+		if !ok {
+			return
+		}
+		if window > 0 {
+			key := CodeFound{CodeType: e.CodeType, Value: e.Value}
+			now := time.Now()
+
+			mu.Lock()
+			last, seen := reported[key]
+			if seen && now.Sub(last) < window {
+				mu.Unlock()
+				return
+			}
+			reported[key] = now
+			// forget codes that are out of the window
+			for k, t := range reported {
+				if now.Sub(t) >= window {
+					delete(reported, k)
+				}
+			}
+			mu.Unlock()
+		}
+		handler(e)
+	})
+}
+
+// Event raised by a `ZBarFilter` when a code is found in the data being
+// streamed.
+type CodeFound struct {
+	MediaEvent
+
+	// type of `QR` code found
+	CodeType string
+
+	// value contained in the `QR` code
+	Value string
+}
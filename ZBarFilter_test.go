@@ -0,0 +1,61 @@
+package kurento
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZBarFilterOnCodeFound(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	filter := &ZBarFilter{}
+	if err := pipeline.Create(filter, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	window := 200 * time.Millisecond
+	deduplicated := make(chan string, 10)
+	if _, err := filter.OnCodeFound(window, func(e *CodeFound) { deduplicated <- e.Value }); err != nil {
+		t.Fatal(err)
+	}
+	all := make(chan string, 10)
+	if _, err := filter.OnCodeFound(0, func(e *CodeFound) { all <- e.Value }); err != nil {
+		t.Fatal(err)
+	}
+	codeFound := func(value string) {
+		f.event(filter.Id, "CodeFound", map[string]interface{}{"codeType": "QR-Code", "value": value})
+	}
+
+	// repeated inside the window
+	codeFound("a")
+	codeFound("a")
+	codeFound("b")
+	codeFound("a")
+	time.Sleep(window + 50*time.Millisecond)
+	// outside the window
+	codeFound("a")
+	codeFound("a")
+
+	receive := func(ch chan string, want []string) {
+		for i, w := range want {
+			select {
+			case v := <-ch:
+				if v != w {
+					t.Errorf("code %d is %q, want %q", i, v, w)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("received %d codes, want %d", i, len(want))
+			}
+		}
+		select {
+		case v := <-ch:
+			t.Errorf("code %q is received more than %d times", v, len(want))
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	receive(deduplicated, []string{"a", "b", "a"})
+	receive(all, []string{"a", "a", "b", "a", "a", "a"})
+}
@@ -0,0 +1,76 @@
+package kurento
+
+// BandwidthProfile is a set of bandwidth limits applied by
+// BaseRtpEndpoint.ApplyBandwidthProfile. Zero values are not applied.
+type BandwidthProfile struct {
+	// Output bitrate of the element, in bps
+	MinOutputBitrate int
+	MaxOutputBitrate int
+
+	// Video encoder bitrate, in kbps. Only supported by recent media servers.
+	MinEncoderBitrate int
+	MaxEncoderBitrate int
+
+	// Video bandwidth for receiving, in kbps
+	MaxVideoRecvBandwidth int
+
+	// Video bandwidth for sending, in kbps
+	MinVideoSendBandwidth int
+	MaxVideoSendBandwidth int
+}
+
+// Bandwidth presets
+var (
+	// Mobile networks, up to 300 kbps of video
+	BandwidthMobile = BandwidthProfile{
+		MinOutputBitrate:      100000,
+		MaxOutputBitrate:      300000,
+		MaxVideoRecvBandwidth: 300,
+		MinVideoSendBandwidth: 100,
+		MaxVideoSendBandwidth: 300,
+	}
+
+	// Standard definition, up to 600 kbps of video
+	BandwidthSD = BandwidthProfile{
+		MinOutputBitrate:      200000,
+		MaxOutputBitrate:      600000,
+		MaxVideoRecvBandwidth: 600,
+		MinVideoSendBandwidth: 200,
+		MaxVideoSendBandwidth: 600,
+	}
+
+	// High definition, up to 2.5 Mbps of video
+	BandwidthHD = BandwidthProfile{
+		MinOutputBitrate:      500000,
+		MaxOutputBitrate:      2500000,
+		MaxVideoRecvBandwidth: 2500,
+		MinVideoSendBandwidth: 500,
+		MaxVideoSendBandwidth: 2500,
+	}
+)
+
+// ApplyBandwidthProfile sets all non zero limits of the profile, e.g.
+// BandwidthSD. It stops at the first error.
+func (elem *BaseRtpEndpoint) ApplyBandwidthProfile(profile BandwidthProfile) error {
+	setters := []struct {
+		value int
+		set   func(int) error
+	}{
+		{profile.MinOutputBitrate, elem.SetMinOutputBitrate},
+		{profile.MaxOutputBitrate, elem.SetMaxOutputBitrate},
+		{profile.MinEncoderBitrate, elem.SetMinEncoderBitrate},
+		{profile.MaxEncoderBitrate, elem.SetMaxEncoderBitrate},
+		{profile.MaxVideoRecvBandwidth, elem.SetMaxVideoRecvBandwidth},
+		{profile.MinVideoSendBandwidth, elem.SetMinVideoSendBandwidth},
+		{profile.MaxVideoSendBandwidth, elem.SetMaxVideoSendBandwidth},
+	}
+	for _, s := range setters {
+		if s.value == 0 {
+			continue
+		}
+		if err := s.set(s.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
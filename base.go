@@ -10,6 +10,9 @@ import (
 var debug = false
 
 // Debug activate debug information.
+//
+// Deprecated: use Connection.SetTracer that writes every message as a JSON
+// line, with sensitive values redacted.
 func Debug(state bool) {
 	debug = state
 }
@@ -22,7 +25,7 @@ type IMediaObject interface {
 
 	// Each media object should be able to create another object
 	// Those options are sent to getConstructorParams
-	Create(IMediaObject, map[string]interface{})
+	Create(IMediaObject, map[string]interface{}) error
 
 	// Set ID of the element
 	setId(string)
@@ -34,13 +37,39 @@ type IMediaObject interface {
 	addChild(IMediaObject)
 
 	setConnection(*Connection)
+
+	// Each media object can receive events from the server
+	Subscribe(string, func(Event)) (string, error)
+	Unsubscribe(string) error
+
+	// Release the object on the server
+	Release() error
+}
+
+// Objects that check their constructor params before they are sent to the
+// server
+type constructorValidator interface {
+	validateConstructorParams(map[string]interface{}) error
 }
 
-// Create object "m" with given "options"
-func (elem *MediaObject) Create(m IMediaObject, options map[string]interface{}) {
+// Create object "m" with given "options". Returns an error if the params are
+// not valid or if the server fails to create the object. When the pipeline
+// tracks connections, an error is also returned if the created object can't be
+// tracked.
+func (elem *MediaObject) Create(m IMediaObject, options map[string]interface{}) error {
 	req := elem.getCreateRequest()
-	constparams := m.getConstructorParams(elem, options)
-	// TODO params["sessionId"]
+	if err := elem.connection.checkModule(m); err != nil {
+		return err
+	}
+	constparams := getConstructorParams(m, elem, options)
+	if v, ok := m.(constructorValidator); ok {
+		if err := v.validateConstructorParams(constparams); err != nil {
+			return err
+		}
+	}
+	if err := elem.connection.checkCreate(getMediaElementType(m), constparams); err != nil {
+		return err
+	}
 	req["params"] = map[string]interface{}{
 		"type":              getMediaElementType(m),
 		"constructorParams": constparams,
@@ -61,7 +90,56 @@ func (elem *MediaObject) Create(m IMediaObject, options map[string]interface{})
 		elem.addChild(m)
 		//m.setParent(elem)
 		m.setId(res.Result["value"])
+		if elem.graph != nil && res.Error == nil {
+			// hubs track their ports in the same graph
+			if h, ok := m.(graphHolder); ok {
+				h.setGraph(elem.graph)
+			}
+			if err := elem.graph.track(m); err != nil {
+				return fmt.Errorf("kurento: %s is created but its connections are not tracked: %v", m, err)
+			}
+		}
 	}
+	return res.Err()
+}
+
+// Release explicitly the object in the media server. Local event handlers of
+// the object are removed.
+func (elem *MediaObject) Release() error {
+	req := elem.getReleaseRequest()
+
+	req["params"] = map[string]interface{}{
+		"object": elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	elem.connection.dropSubscriptions(elem.Id)
+
+	// Returns error or nil
+	return response.Err()
+}
+
+// Call a property setter of the object, e.g. "setTurnUrl" with "turnUrl"
+func (elem *MediaObject) setProperty(operation string, name string, value interface{}) error {
+	req := elem.getInvokeRequest()
+
+	params := map[string]interface{}{
+		name: value,
+	}
+
+	req["params"] = map[string]interface{}{
+		"operation":       operation,
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// Returns error or nil
+	return response.Err()
 }
 
 // Implement setConnection that allows element to handle connection
@@ -103,13 +181,56 @@ func (m *MediaObject) getInvokeRequest() map[string]interface{} {
 	return req
 }
 
+// Build a prepared subscribe request
+func (m *MediaObject) getSubscribeRequest() map[string]interface{} {
+	req := m.getCreateRequest()
+	req["method"] = "subscribe"
+
+	return req
+}
+
+// Build a prepared unsubscribe request
+func (m *MediaObject) getUnsubscribeRequest() map[string]interface{} {
+	req := m.getCreateRequest()
+	req["method"] = "unsubscribe"
+
+	return req
+}
+
+// Build a prepared release request
+func (m *MediaObject) getReleaseRequest() map[string]interface{} {
+	req := m.getCreateRequest()
+	req["method"] = "release"
+
+	return req
+}
+
 // String implements fmt.Stringer interface, return ID
 func (m *MediaObject) String() string {
 	return m.Id
 }
 
+// Return constructor params of m, types registered with RegisterType can't
+// implement getConstructorParams
+func getConstructorParams(m IMediaObject, from IMediaObject, options map[string]interface{}) map[string]interface{} {
+	if c, ok := m.(CustomConstructor); ok {
+		return c.ConstructorParams(from, options)
+	}
+	if _, ok := registeredTypeName(m); ok {
+		ret := map[string]interface{}{
+			"mediaPipeline": fmt.Sprintf("%s", from),
+		}
+		mergeOptions(ret, options)
+		return ret
+	}
+	return m.getConstructorParams(from, options)
+}
+
 // Return name of the object
 func getMediaElementType(i interface{}) string {
+	if n, ok := registeredTypeName(i); ok {
+		return n
+	}
 	n := reflect.TypeOf(i).String()
 	p := strings.Split(n, ".")
 	return p[len(p)-1]
@@ -128,10 +249,14 @@ func setIfNotEmpty(param map[string]interface{}, name string, t interface{}) {
 		if v != "" {
 			param[name] = v
 		}
-	case int, float64:
+	case int:
 		if v != 0 {
 			param[name] = v
 		}
+	case float64:
+		if v != 0.0 {
+			param[name] = v
+		}
 	case bool:
 		if v {
 			param[name] = v
@@ -143,5 +268,20 @@ func setIfNotEmpty(param map[string]interface{}, name string, t interface{}) {
 				param[name] = val
 			}
 		}
+	default:
+		// other numbers, and complex types such as IceCandidate that are
+		// encoded with their JSON tags when the request is sent
+		rv := reflect.ValueOf(t)
+		switch rv.Kind() {
+		case reflect.Struct, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32:
+			if !rv.IsZero() {
+				param[name] = t
+			}
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if !rv.IsNil() && (rv.Kind() == reflect.Ptr || rv.Len() > 0) {
+				param[name] = t
+			}
+		}
 	}
 }
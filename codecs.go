@@ -0,0 +1,220 @@
+package kurento
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CodecPreferences orders the codecs of SDP offers and answers, e.g. to force
+// H264 for clients that don't support VP8. Set it in the CodecPreferences
+// field of an `SdpEndpoint`, or use ApplyCodecPreferences.
+type CodecPreferences struct {
+	// Video codecs, the first one is preferred. Other codecs follow them.
+	Video []VideoCodec
+
+	// Audio codecs, the first one is preferred. Other codecs follow them.
+	Audio []AudioCodec
+
+	// Remove the codecs that are not listed from media sections that have
+	// preferences
+	Strip bool
+}
+
+// Check if there are no preferences
+func (p CodecPreferences) empty() bool {
+	return len(p.Video) == 0 && len(p.Audio) == 0
+}
+
+// Payloads that are not media codecs. They are kept when codecs are
+// stripped, except RTX and RED payloads of removed codecs.
+var auxiliaryCodecs = map[string]bool{
+	"RTX":             true,
+	"RED":             true,
+	"ULPFEC":          true,
+	"FLEXFEC-03":      true,
+	"TELEPHONE-EVENT": true,
+	"CN":              true,
+}
+
+// Codecs of static payload types, that may be used without "a=rtpmap"
+var staticPayloads = map[string]string{
+	"0": "PCMU",
+	"8": "PCMA",
+	"9": "G722",
+}
+
+// ApplyCodecPreferences reorders the payloads of the audio and video media
+// sections of sdp, and removes codecs that are not listed if prefs.Strip is
+// set. It returns an error if a media section would have no codec left.
+func ApplyCodecPreferences(sdp string, prefs CodecPreferences) (string, error) {
+	if prefs.empty() {
+		return sdp, nil
+	}
+
+	eol := "\n"
+	if strings.Contains(sdp, "\r\n") {
+		eol = "\r\n"
+	}
+	lines := strings.Split(strings.TrimRight(sdp, "\r\n"), eol)
+
+	// split lines into the session section and media sections
+	sections := [][]string{{}}
+	for _, l := range lines {
+		if strings.HasPrefix(l, "m=") {
+			sections = append(sections, []string{})
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], l)
+	}
+
+	out := sections[0]
+	for _, section := range sections[1:] {
+		kind := strings.TrimPrefix(strings.SplitN(section[0], " ", 2)[0], "m=")
+		names := []string{}
+		switch kind {
+		case "video":
+			for _, c := range prefs.Video {
+				names = append(names, c.String())
+			}
+		case "audio":
+			for _, c := range prefs.Audio {
+				names = append(names, c.String())
+			}
+		}
+		if len(names) == 0 {
+			out = append(out, section...)
+			continue
+		}
+		section, err := orderPayloads(section, names, prefs.Strip)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, section...)
+	}
+	return strings.Join(out, eol) + eol, nil
+}
+
+// Reorder the payloads of a media section after the codec names
+func orderPayloads(section []string, names []string, strip bool) ([]string, error) {
+	// m=<media> <port> <proto> <payloads>...
+	mline := strings.Fields(section[0])
+	if len(mline) < 4 {
+		return section, nil
+	}
+	payloads := mline[3:]
+
+	// codec name, associated payload of RTX and redundant payloads of RED
+	// ("a=fmtp:63 111/111"), by payload type
+	codecs := map[string]string{}
+	for pt, name := range staticPayloads {
+		codecs[pt] = name
+	}
+	associated := map[string]string{}
+	fmtps := map[string]string{}
+	for _, l := range section[1:] {
+		if pt, value, ok := sdpAttribute(l, "rtpmap"); ok {
+			codecs[pt] = strings.ToUpper(strings.SplitN(value, "/", 2)[0])
+		}
+		if pt, value, ok := sdpAttribute(l, "fmtp"); ok {
+			fmtps[pt] = value
+			for _, p := range strings.Split(value, ";") {
+				if kv := strings.SplitN(strings.TrimSpace(p), "=", 2); len(kv) == 2 && kv[0] == "apt" {
+					associated[pt] = kv[1]
+				}
+			}
+		}
+	}
+	redundant := map[string][]string{}
+	for pt, value := range fmtps {
+		if codecs[pt] == "RED" && !strings.Contains(value, "=") {
+			redundant[pt] = strings.Split(strings.TrimSpace(value), "/")
+		}
+	}
+
+	// preferred codecs first, each followed by its RTX payloads
+	ordered := []string{}
+	added := map[string]bool{}
+	add := func(pt string) {
+		if added[pt] {
+			return
+		}
+		added[pt] = true
+		ordered = append(ordered, pt)
+		for _, rtx := range payloads {
+			if associated[rtx] == pt && !added[rtx] {
+				added[rtx] = true
+				ordered = append(ordered, rtx)
+			}
+		}
+	}
+	for _, name := range names {
+		for _, pt := range payloads {
+			if codecs[pt] == strings.ToUpper(name) {
+				add(pt)
+			}
+		}
+	}
+	if strip && len(ordered) == 0 {
+		return nil, fmt.Errorf("kurento: no codec of %s is left in %q media", strings.Join(names, ", "), strings.TrimPrefix(mline[0], "m="))
+	}
+
+	// then the other payloads, auxiliary ones only if codecs are stripped
+	for _, pt := range payloads {
+		if added[pt] {
+			continue
+		}
+		if apt, ok := associated[pt]; ok {
+			if added[apt] || !strip {
+				add(pt)
+			}
+			continue
+		}
+		if !strip {
+			add(pt)
+			continue
+		}
+		if red, ok := redundant[pt]; ok {
+			for _, r := range red {
+				if added[r] {
+					add(pt)
+					break
+				}
+			}
+			continue
+		}
+		if auxiliaryCodecs[codecs[pt]] {
+			add(pt)
+		}
+	}
+
+	ret := []string{strings.Join(append(mline[:3:3], ordered...), " ")}
+	for _, l := range section[1:] {
+		if pt, ok := sdpPayloadOf(l); ok && !added[pt] {
+			continue
+		}
+		ret = append(ret, l)
+	}
+	return ret, nil
+}
+
+// Return the payload type and value of an "a=<name>:<pt> <value>" line
+func sdpAttribute(line string, name string) (string, string, bool) {
+	prefix := "a=" + name + ":"
+	if !strings.HasPrefix(line, prefix) {
+		return "", "", false
+	}
+	kv := strings.SplitN(strings.TrimPrefix(line, prefix), " ", 2)
+	if len(kv) != 2 {
+		return "", "", false
+	}
+	return kv[0], kv[1], true
+}
+
+// Return the payload type of rtpmap, fmtp and rtcp-fb lines
+func sdpPayloadOf(line string) (string, bool) {
+	for _, name := range []string{"rtpmap", "fmtp", "rtcp-fb"} {
+		if pt, _, ok := sdpAttribute(line, name); ok && pt != "*" {
+			return pt, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,223 @@
+package kurento
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Offer of Chrome, with RTX for each video codec, RED and ULPFEC for video,
+// and RED for audio
+var chromeOffer = strings.Join([]string{
+	"v=0",
+	"o=- 4611731400430051336 2 IN IP4 127.0.0.1",
+	"s=-",
+	"t=0 0",
+	"a=group:BUNDLE 0 1",
+	"a=msid-semantic: WMS",
+	"m=audio 9 UDP/TLS/RTP/SAVPF 111 63 9 0 8 13 110 126",
+	"c=IN IP4 0.0.0.0",
+	"a=rtcp:9 IN IP4 0.0.0.0",
+	"a=mid:0",
+	"a=sendrecv",
+	"a=rtcp-mux",
+	"a=rtpmap:111 opus/48000/2",
+	"a=rtcp-fb:111 transport-cc",
+	"a=fmtp:111 minptime=10;useinbandfec=1",
+	"a=rtpmap:63 red/48000/2",
+	"a=fmtp:63 111/111",
+	"a=rtpmap:9 G722/8000",
+	"a=rtpmap:0 PCMU/8000",
+	"a=rtpmap:8 PCMA/8000",
+	"a=rtpmap:13 CN/8000",
+	"a=rtpmap:110 telephone-event/48000",
+	"a=rtpmap:126 telephone-event/8000",
+	"m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99 102 103 116 117 118",
+	"c=IN IP4 0.0.0.0",
+	"a=rtcp:9 IN IP4 0.0.0.0",
+	"a=mid:1",
+	"a=sendrecv",
+	"a=rtcp-mux",
+	"a=rtcp-rsize",
+	"a=rtpmap:96 VP8/90000",
+	"a=rtcp-fb:96 goog-remb",
+	"a=rtcp-fb:96 nack",
+	"a=rtpmap:97 rtx/90000",
+	"a=fmtp:97 apt=96",
+	"a=rtpmap:98 VP9/90000",
+	"a=rtcp-fb:98 nack",
+	"a=fmtp:98 profile-id=0",
+	"a=rtpmap:99 rtx/90000",
+	"a=fmtp:99 apt=98",
+	"a=rtpmap:102 H264/90000",
+	"a=rtcp-fb:102 nack",
+	"a=fmtp:102 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42001f",
+	"a=rtpmap:103 rtx/90000",
+	"a=fmtp:103 apt=102",
+	"a=rtpmap:116 red/90000",
+	"a=rtpmap:117 rtx/90000",
+	"a=fmtp:117 apt=116",
+	"a=rtpmap:118 ulpfec/90000",
+	"a=ssrc-group:FID 1396283186 3129506843",
+	"",
+}, "\r\n")
+
+// Return the m= line of a media type
+func sdpMediaLine(sdp string, media string) string {
+	for _, l := range strings.Split(sdp, "\r\n") {
+		if strings.HasPrefix(l, "m="+media+" ") {
+			return l
+		}
+	}
+	return ""
+}
+
+func TestApplyCodecPreferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs CodecPreferences
+		audio string
+		video string
+		// lines that must be removed
+		removed []string
+	}{
+		{
+			name:  "no preferences",
+			prefs: CodecPreferences{},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 111 63 9 0 8 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99 102 103 116 117 118",
+		},
+		{
+			name:  "reorder video",
+			prefs: CodecPreferences{Video: []VideoCodec{VIDEOCODEC_VP9, VIDEOCODEC_H264}},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 111 63 9 0 8 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 98 99 102 103 96 97 116 117 118",
+		},
+		{
+			name:  "strip video",
+			prefs: CodecPreferences{Video: []VideoCodec{VIDEOCODEC_H264}, Strip: true},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 111 63 9 0 8 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 102 103 116 117 118",
+			removed: []string{
+				"a=rtpmap:96 VP8/90000", "a=rtcp-fb:96 nack", "a=fmtp:97 apt=96",
+				"a=rtpmap:98 VP9/90000", "a=fmtp:98 profile-id=0", "a=fmtp:99 apt=98",
+			},
+		},
+		{
+			name:  "codec names are not case sensitive",
+			prefs: CodecPreferences{Video: []VideoCodec{"h264"}, Strip: true},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 111 63 9 0 8 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 102 103 116 117 118",
+		},
+		{
+			name:  "reorder audio with static payloads",
+			prefs: CodecPreferences{Audio: []AudioCodec{AUDIOCODEC_PCMA, AUDIOCODEC_PCMU}},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 8 0 111 63 9 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99 102 103 116 117 118",
+		},
+		{
+			name:  "strip audio",
+			prefs: CodecPreferences{Audio: []AudioCodec{AUDIOCODEC_G722}, Strip: true},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 9 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99 102 103 116 117 118",
+			removed: []string{
+				"a=rtpmap:111 opus/48000/2", "a=rtcp-fb:111 transport-cc", "a=fmtp:111 minptime=10;useinbandfec=1",
+				"a=rtpmap:63 red/48000/2", "a=fmtp:63 111/111",
+			},
+		},
+		{
+			name:  "keep audio RED of a kept codec",
+			prefs: CodecPreferences{Audio: []AudioCodec{AUDIOCODEC_OPUS}, Strip: true},
+			audio: "m=audio 9 UDP/TLS/RTP/SAVPF 111 63 13 110 126",
+			video: "m=video 9 UDP/TLS/RTP/SAVPF 96 97 98 99 102 103 116 117 118",
+			removed: []string{
+				"a=rtpmap:9 G722/8000", "a=rtpmap:0 PCMU/8000", "a=rtpmap:8 PCMA/8000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		out, err := ApplyCodecPreferences(chromeOffer, tt.prefs)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := sdpMediaLine(out, "audio"); got != tt.audio {
+			t.Errorf("%s: audio is %q, want %q", tt.name, got, tt.audio)
+		}
+		if got := sdpMediaLine(out, "video"); got != tt.video {
+			t.Errorf("%s: video is %q, want %q", tt.name, got, tt.video)
+		}
+		for _, l := range tt.removed {
+			if strings.Contains(out, l+"\r\n") {
+				t.Errorf("%s: %q is not removed", tt.name, l)
+			}
+		}
+		// other lines are kept
+		for _, l := range []string{"a=group:BUNDLE 0 1", "a=rtcp-rsize", "a=ssrc-group:FID 1396283186 3129506843"} {
+			if !strings.Contains(out, l+"\r\n") {
+				t.Errorf("%s: %q is removed", tt.name, l)
+			}
+		}
+		if !strings.HasSuffix(out, "\r\n") || strings.Contains(strings.Replace(out, "\r\n", "", -1), "\n") {
+			t.Errorf("%s: line endings are changed", tt.name)
+		}
+	}
+}
+
+func TestApplyCodecPreferencesNoCodecLeft(t *testing.T) {
+	prefs := CodecPreferences{Video: []VideoCodec{VIDEOCODEC_H265}, Strip: true}
+	if _, err := ApplyCodecPreferences(chromeOffer, prefs); err == nil {
+		t.Error("ApplyCodecPreferences should fail when no codec is left")
+	}
+}
+
+func TestCodecUnmarshalText(t *testing.T) {
+	var video []VideoCodec
+	if err := json.Unmarshal([]byte(`["vp8","H264","h265","AV1"]`), &video); err != nil {
+		t.Fatal(err)
+	}
+	want := []VideoCodec{VIDEOCODEC_VP8, VIDEOCODEC_H264, VIDEOCODEC_H265, "AV1"}
+	for i := range want {
+		if video[i] != want[i] {
+			t.Errorf("video codec %d is %q, want %q", i, video[i], want[i])
+		}
+	}
+
+	var audio []AudioCodec
+	if err := json.Unmarshal([]byte(`["opus","g722","AMR","iLBC"]`), &audio); err != nil {
+		t.Fatal(err)
+	}
+	wantAudio := []AudioCodec{AUDIOCODEC_OPUS, AUDIOCODEC_G722, AUDIOCODEC_AMR, "iLBC"}
+	for i := range wantAudio {
+		if audio[i] != wantAudio[i] {
+			t.Errorf("audio codec %d is %q, want %q", i, audio[i], wantAudio[i])
+		}
+	}
+}
+
+func TestSdpEndpointCodecPreferences(t *testing.T) {
+	f := newFake(t)
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] == "processOffer" {
+			return map[string]interface{}{"value": operationParamsOf(req)["offer"]}
+		}
+		return nil
+	}
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+	webrtc.CodecPreferences = CodecPreferences{Video: []VideoCodec{VIDEOCODEC_H264}, Strip: true}
+
+	answer, err := webrtc.ProcessOffer(chromeOffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sdpMediaLine(answer, "video"), "m=video 9 UDP/TLS/RTP/SAVPF 102 103 116 117 118"; got != want {
+		t.Errorf("video is %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package kurento
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version of the media server API. The package has the types, constructor
+// params, operations and events of both KMS 5 and KMS 6, the API version of a
+// connection tells which ones the server doesn't support, and the names of
+// some events.
+type APIVersion string
+
+// Implement fmt.Stringer interface
+func (t APIVersion) String() string {
+	return string(t)
+}
+
+const (
+	// Detect the API version from the server version
+	APIVERSION_AUTO APIVersion = ""
+	APIVERSION_5    APIVersion = "5"
+	APIVERSION_6    APIVersion = "6"
+)
+
+// JSON-RPC code of errors returned for operations that the server doesn't
+// support, see IsUnsupported
+const ERRORCODE_UNSUPPORTED = -32601
+
+// Types, constructor params ("Type.param"), operations and events that are
+// missing in an API version. Only names known to be missing are listed, the
+// server answers for the others.
+var unsupportedByAPI = map[APIVersion]map[string]bool{
+	APIVERSION_5: {
+		"WebRtcEndpoint.useDataChannels":    true,
+		"WebRtcEndpoint.certificateKeyType": true,
+		"PlayerEndpoint.networkCache":       true,
+		"getStats":                          true,
+		"createDataChannel":                 true,
+		"closeDataChannel":                  true,
+		"setExternalAddress":                true,
+		"isMediaFlowingIn":                  true,
+		"isMediaFlowingOut":                 true,
+		"getConnectionState":                true,
+		"getMediaState":                     true,
+		"MediaFlowInStateChange":            true,
+		"MediaFlowOutStateChange":           true,
+		"ConnectionStateChanged":            true,
+		"MediaStateChanged":                 true,
+		"IceComponentStateChange":           true,
+		"NewCandidatePairSelected":          true,
+		"DataChannelOpened":                 true,
+		"DataChannelClosed":                 true,
+	},
+	APIVERSION_6: {
+		"HttpGetEndpoint":     true,
+		"getMediaSrcs":        true,
+		"getMediaSinks":       true,
+		"getConnectedSinks":   true,
+		"getConnectedSrc":     true,
+		"getMediaDescription": true,
+	},
+}
+
+// Names of events that differ from the package names, by API version
+var eventNamesByAPI = map[APIVersion]map[string]string{
+	APIVERSION_5: {
+		"IceCandidateFound": "OnIceCandidate",
+		"IceGatheringDone":  "OnIceGatheringDone",
+	},
+	APIVERSION_6: {
+		"DataChannelOpened": "OnDataChannelOpened",
+		"DataChannelClosed": "OnDataChannelClosed",
+	},
+}
+
+// SetAPIVersion selects the API of the media server. With APIVERSION_AUTO,
+// the default, the version is detected from ServerInfo the first time it is
+// needed.
+func (c *Connection) SetAPIVersion(v APIVersion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiVersion = v
+	c.apiVersionErr = nil
+}
+
+// APIVersion returns the API version of the media server, as set by
+// SetAPIVersion or detected from the server version. The detection is only
+// tried once: if it fails, the same error is returned until SetAPIVersion is
+// called, and the server answers for all types and operations.
+func (c *Connection) APIVersion() (APIVersion, error) {
+	c.mu.Lock()
+	v, err := c.apiVersion, c.apiVersionErr
+	c.mu.Unlock()
+	if v != APIVERSION_AUTO || err != nil {
+		return v, err
+	}
+
+	info, err := c.ServerInfo()
+	if err == nil {
+		v, err = apiVersionOf(info.Version)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.apiVersion != APIVERSION_AUTO {
+		// set by SetAPIVersion in the meantime
+		return c.apiVersion, nil
+	}
+	if err != nil {
+		c.apiVersionErr = fmt.Errorf("kurento: cannot detect the API version: %v", err)
+		return APIVERSION_AUTO, c.apiVersionErr
+	}
+	c.apiVersion = v
+	return v, nil
+}
+
+// Return the API version of a server version, e.g. "6.18.0". Versions after 6
+// use the KMS 6 API.
+func apiVersionOf(serverVersion string) (APIVersion, error) {
+	major, err := strconv.Atoi(strings.SplitN(serverVersion, ".", 2)[0])
+	switch {
+	case err != nil:
+		return APIVERSION_AUTO, fmt.Errorf("kurento: invalid server version %q", serverVersion)
+	case major < 5:
+		return APIVERSION_AUTO, fmt.Errorf("kurento: media server %s is not supported", serverVersion)
+	case major == 5:
+		return APIVERSION_5, nil
+	}
+	return APIVERSION_6, nil
+}
+
+// IsUnsupported checks if err is returned because the media server doesn't
+// support a type, a constructor param or an operation.
+func IsUnsupported(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == ERRORCODE_UNSUPPORTED
+}
+
+// Return an error if name is not supported by the API of the server. The API
+// version is only detected for names that are missing in one version. If it
+// can't be detected, the server will answer.
+func (c *Connection) checkAPI(name string) *Error {
+	known := false
+	for _, names := range unsupportedByAPI {
+		known = known || names[name]
+	}
+	if !known {
+		return nil
+	}
+
+	v, err := c.APIVersion()
+	if err != nil || !unsupportedByAPI[v][name] {
+		return nil
+	}
+	return &Error{
+		Code:    ERRORCODE_UNSUPPORTED,
+		Message: "Not supported by the media server",
+		Data:    fmt.Sprintf("%s is not available in the KMS %s API", name, v),
+	}
+}
+
+// Check that the type and the constructor params are supported
+func (c *Connection) checkCreate(typeName string, params map[string]interface{}) error {
+	if err := c.checkAPI(typeName); err != nil {
+		return err
+	}
+	for name, value := range params {
+		if value == false {
+			continue
+		}
+		if err := c.checkAPI(typeName + "." + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check the operation of an "invoke" request, and the event of a "subscribe"
+// request
+func (c *Connection) checkRequest(req map[string]interface{}) *Error {
+	params, _ := req["params"].(map[string]interface{})
+	switch req["method"] {
+	case "invoke":
+		operation, _ := params["operation"].(string)
+		return c.checkAPI(operation)
+	case "subscribe":
+		eventType, _ := params["type"].(string)
+		return c.checkAPI(packageEventName(eventType))
+	}
+	return nil
+}
+
+// Return the name used by the server for an event of the package
+func (c *Connection) serverEventName(eventType string) string {
+	for _, names := range eventNamesByAPI {
+		if _, ok := names[eventType]; !ok {
+			continue
+		}
+		if v, err := c.APIVersion(); err == nil {
+			if name, ok := eventNamesByAPI[v][eventType]; ok {
+				return name
+			}
+		}
+		break
+	}
+	return eventType
+}
+
+// Return the package name of an event received from the server
+func packageEventName(eventType string) string {
+	for _, names := range eventNamesByAPI {
+		for name, serverName := range names {
+			if serverName == eventType {
+				return name
+			}
+		}
+	}
+	return eventType
+}
+
+// Connect sends the "connect" request of the KMS 6 protocol on the websocket of
+// the connection, with SessionId if it is set. The server answers with the
+// session id, that is kept for the next requests. The websocket is not reopened
+// if it is closed. KMS 5 doesn't support it.
+func (c *Connection) Connect() error {
+	v, err := c.APIVersion()
+	if err != nil {
+		return err
+	}
+	if v == APIVERSION_5 {
+		return &Error{
+			Code:    ERRORCODE_UNSUPPORTED,
+			Message: "Not supported by the media server",
+			Data:    "connect is not available in the KMS 5 API",
+		}
+	}
+
+	req := (&MediaObject{}).getCreateRequest()
+	req["method"] = "connect"
+
+	// Call server and wait response, the session id is kept by the reader
+	response := <-c.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+}
@@ -0,0 +1,351 @@
+package kurento
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Answer requests like a media server of the given version
+func fakeVersion(f *fakeKMS, version string) {
+	f.reply = func(req map[string]interface{}) interface{} {
+		switch paramsOf(req)["operation"] {
+		case "getInfo":
+			return map[string]interface{}{"value": map[string]interface{}{"version": version, "modules": []interface{}{}}}
+		case "getMediaSrcs":
+			return map[string]interface{}{"value": []string{}}
+		}
+		return nil
+	}
+}
+
+func TestAPIVersionOf(t *testing.T) {
+	tests := map[string]APIVersion{
+		"5.1.3": APIVERSION_5,
+		"6.0.0": APIVERSION_6,
+		"7.0.1": APIVERSION_6,
+		"4.3":   APIVERSION_AUTO,
+		"dev":   APIVERSION_AUTO,
+	}
+	for version, want := range tests {
+		if v, _ := apiVersionOf(version); v != want {
+			t.Errorf("apiVersionOf(%q) = %q, want %q", version, v, want)
+		}
+	}
+}
+
+func TestAPIVersion5(t *testing.T) {
+	f := newFake(t)
+	fakeVersion(f, "5.1.0")
+	c := f.conn()
+	if v, err := c.APIVersion(); err != nil || v != APIVERSION_5 {
+		t.Fatalf("APIVersion() = %q, %v", v, err)
+	}
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Create(&WebRtcEndpoint{UseDataChannels: true}, nil); !IsUnsupported(err) {
+		t.Errorf("useDataChannels should not be supported, got %v", err)
+	}
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// unsupported operations are not sent
+	n := len(f.reqs)
+	if _, err := webrtc.GetStats(""); !IsUnsupported(err) {
+		t.Errorf("GetStats should not be supported, got %v", err)
+	}
+	if err := c.Connect(); !IsUnsupported(err) {
+		t.Errorf("Connect should not be supported, got %v", err)
+	}
+	if len(f.reqs) != n {
+		t.Error("unsupported requests were sent")
+	}
+
+	// operations of both versions are sent
+	if _, err := webrtc.GetMediaSrcs("", ""); err != nil {
+		t.Error(err)
+	}
+	if _, err := webrtc.GetSourceConnections("", ""); IsUnsupported(err) {
+		t.Error(err)
+	}
+	if session := paramsOf(f.last())["sessionId"]; session != "s1" {
+		t.Errorf("sessionId = %v, want s1", session)
+	}
+}
+
+func TestAPIVersion6(t *testing.T) {
+	f := newFake(t)
+	fakeVersion(f, "6.18.0")
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Create(&HttpGetEndpoint{}, nil); !IsUnsupported(err) {
+		t.Errorf("HttpGetEndpoint should not be supported, got %v", err)
+	}
+
+	// constructor params have their default value
+	if err := pipeline.Create(&PlayerEndpoint{}, map[string]interface{}{"uri": "file:///tmp/a.webm"}); err != nil {
+		t.Fatal(err)
+	}
+	params := paramsOf(f.last())["constructorParams"]
+	want := map[string]interface{}{
+		"mediaPipeline":   pipeline.Id,
+		"uri":             "file:///tmp/a.webm",
+		"useEncodedMedia": false,
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("constructorParams = %v, want %v", params, want)
+	}
+
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webrtc.GetMediaSrcs("", ""); !IsUnsupported(err) {
+		t.Errorf("GetMediaSrcs should not be supported, got %v", err)
+	}
+
+	// events are renamed both ways
+	got := make(chan Event, 1)
+	if _, err := webrtc.Subscribe("DataChannelOpened", func(ev Event) { got <- ev }); err != nil {
+		t.Fatal(err)
+	}
+	if typ := paramsOf(f.last())["type"]; typ != "OnDataChannelOpened" {
+		t.Errorf("subscribed to %v, want OnDataChannelOpened", typ)
+	}
+	f.event(webrtc.Id, "OnDataChannelOpened", map[string]interface{}{"channelId": 3})
+	select {
+	case ev := <-got:
+		if e, ok := ev.(*DataChannelOpened); !ok || e.Type != "DataChannelOpened" {
+			t.Errorf("received %#v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if method := f.last()["method"]; method != "connect" {
+		t.Errorf("method = %v, want connect", method)
+	}
+}
+
+func TestAPIVersionDetectionFailure(t *testing.T) {
+	f := newFake(t)
+	getInfo := 0
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] == "getInfo" {
+			getInfo++
+			return &Error{Code: -32000, Message: "Unexpected error"}
+		}
+		return nil
+	}
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.APIVersion(); err == nil {
+			t.Error("APIVersion should fail")
+		}
+	}
+	// the server answers
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+	webrtc.GetStats("")
+	if operation := paramsOf(f.last())["operation"]; operation != "getStats" {
+		t.Errorf("last operation = %v, want getStats", operation)
+	}
+
+	f.mu.Lock()
+	n := getInfo
+	f.mu.Unlock()
+	if n != 1 {
+		t.Errorf("getInfo is sent %d times, want 1", n)
+	}
+
+	c.SetAPIVersion(APIVERSION_5)
+	if v, err := c.APIVersion(); err != nil || v != APIVERSION_5 {
+		t.Errorf("APIVersion() = %q, %v after SetAPIVersion", v, err)
+	}
+}
+
+func TestAPIVersion5Types(t *testing.T) {
+	f := newFake(t)
+	fakeVersion(f, "5.1.0")
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Create(&WebRtcEndpoint{CertificateKeyType: CERTIFICATEKEYTYPE_ECDSA}, nil); !IsUnsupported(err) {
+		t.Errorf("certificateKeyType should not be supported, got %v", err)
+	}
+	if err := pipeline.Create(&PlayerEndpoint{NetworkCache: 500}, nil); !IsUnsupported(err) {
+		t.Errorf("networkCache should not be supported, got %v", err)
+	}
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(f.reqs)
+	if _, err := webrtc.IsMediaFlowingIn(MEDIATYPE_VIDEO, ""); !IsUnsupported(err) {
+		t.Errorf("IsMediaFlowingIn should not be supported, got %v", err)
+	}
+	if _, err := webrtc.GetConnectionState(); !IsUnsupported(err) {
+		t.Errorf("GetConnectionState should not be supported, got %v", err)
+	}
+	if _, err := webrtc.Subscribe("ConnectionStateChanged", func(Event) {}); !IsUnsupported(err) {
+		t.Errorf("ConnectionStateChanged should not be supported, got %v", err)
+	}
+	if len(f.reqs) != n {
+		t.Error("unsupported requests were sent")
+	}
+
+	// KMS 5 names of ICE events
+	got := make(chan Event, 1)
+	if _, err := webrtc.Subscribe("IceCandidateFound", func(ev Event) { got <- ev }); err != nil {
+		t.Fatal(err)
+	}
+	if typ := paramsOf(f.last())["type"]; typ != "OnIceCandidate" {
+		t.Errorf("subscribed to %v, want OnIceCandidate", typ)
+	}
+	f.event(webrtc.Id, "OnIceCandidate", map[string]interface{}{
+		"candidate": map[string]interface{}{"candidate": "candidate:1", "sdpMid": "0", "sdpMLineIndex": 0},
+	})
+	select {
+	case ev := <-got:
+		if e, ok := ev.(*IceCandidateFound); !ok || e.Type != "IceCandidateFound" || e.Candidate.Candidate != "candidate:1" {
+			t.Errorf("received %#v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
+
+func TestAPIVersion6Types(t *testing.T) {
+	f := newFake(t)
+	fakeVersion(f, "6.18.0")
+	getInfo := f.reply
+	f.reply = func(req map[string]interface{}) interface{} {
+		switch paramsOf(req)["operation"] {
+		case "isMediaFlowingIn":
+			return map[string]interface{}{"value": true}
+		case "getConnectionState":
+			return map[string]interface{}{"value": "CONNECTED"}
+		}
+		return getInfo(req)
+	}
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pipeline.Create(&PlayerEndpoint{NetworkCache: 500}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cache := paramsOf(f.last())["constructorParams"].(map[string]interface{})["networkCache"]; cache != 500.0 {
+		t.Errorf("networkCache = %v, want 500", cache)
+	}
+	webrtc := &WebRtcEndpoint{CertificateKeyType: CERTIFICATEKEYTYPE_ECDSA}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if key := paramsOf(f.last())["constructorParams"].(map[string]interface{})["certificateKeyType"]; key != "ECDSA" {
+		t.Errorf("certificateKeyType = %v, want ECDSA", key)
+	}
+
+	if flowing, err := webrtc.IsMediaFlowingIn(MEDIATYPE_VIDEO, ""); err != nil || !flowing {
+		t.Errorf("IsMediaFlowingIn() = %v, %v", flowing, err)
+	}
+	if p := operationParamsOf(f.last()); p["mediaType"] != "VIDEO" {
+		t.Errorf("operationParams = %v", p)
+	}
+	if state, err := webrtc.GetConnectionState(); err != nil || state != CONNECTIONSTATE_CONNECTED {
+		t.Errorf("GetConnectionState() = %v, %v", state, err)
+	}
+
+	got := make(chan Event, 2)
+	for _, typ := range []string{"MediaFlowInStateChange", "IceCandidateFound"} {
+		if _, err := webrtc.Subscribe(typ, func(ev Event) { got <- ev }); err != nil {
+			t.Fatal(err)
+		}
+		if name := paramsOf(f.last())["type"]; name != typ {
+			t.Errorf("subscribed to %v, want %s", name, typ)
+		}
+	}
+	f.event(webrtc.Id, "MediaFlowInStateChange", map[string]interface{}{"state": "FLOWING", "padName": "default", "mediaType": "VIDEO"})
+	f.event(webrtc.Id, "IceCandidateFound", map[string]interface{}{
+		"candidate": map[string]interface{}{"candidate": "candidate:1", "sdpMid": "0", "sdpMLineIndex": 0},
+	})
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-got:
+			switch e := ev.(type) {
+			case *MediaFlowInStateChange:
+				if e.State != MEDIAFLOWSTATE_FLOWING || e.PadName != "default" || e.MediaType != MEDIATYPE_VIDEO {
+					t.Errorf("received %#v", e)
+				}
+			case *IceCandidateFound:
+				if e.Candidate.Candidate != "candidate:1" {
+					t.Errorf("received %#v", e)
+				}
+			default:
+				t.Errorf("received %#v", ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+}
+
+func TestConnectSession(t *testing.T) {
+	f := newFake(t)
+	fakeVersion(f, "6.18.0")
+	getInfo := f.reply
+	f.reply = func(req map[string]interface{}) interface{} {
+		if req["method"] == "connect" {
+			return map[string]interface{}{"sessionId": "s6"}
+		}
+		if r := getInfo(req); r != nil {
+			return r
+		}
+		return map[string]interface{}{"value": "obj", "sessionId": "s6"}
+	}
+	c := f.conn()
+	c.SessionId = "s5"
+
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	req := f.last()
+	if req["method"] != "connect" || paramsOf(req)["sessionId"] != "s5" {
+		t.Errorf("connect request is %v, want sessionId s5", req)
+	}
+	if c.SessionId != "s6" {
+		t.Errorf("SessionId = %q, want s6", c.SessionId)
+	}
+
+	if err := c.Create(&MediaPipeline{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if session := paramsOf(f.last())["sessionId"]; session != "s6" {
+		t.Errorf("sessionId = %v, want s6", session)
+	}
+}
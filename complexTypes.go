@@ -1,5 +1,10 @@
 package kurento
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // Media Profile.
 // Currently WEBM and MP4 are supported.
 type MediaProfileSpecType string
@@ -19,16 +24,16 @@ const (
 )
 
 type IceCandidate struct {
-	Candidate     string
-	SdpMid        string
-	SdpMLineIndex int
+	Candidate     string `json:"candidate"`
+	SdpMid        string `json:"sdpMid"`
+	SdpMLineIndex int    `json:"sdpMLineIndex"`
 }
 
 type ServerInfo struct {
-	Version      string
-	Modules      []ModuleInfo
-	Type         ServerType
-	Capabilities []string
+	Version      string       `json:"version"`
+	Modules      []ModuleInfo `json:"modules"`
+	Type         ServerType   `json:"type"`
+	Capabilities []string     `json:"capabilities"`
 }
 
 // Indicates if the server is a real media server or a proxy
@@ -45,9 +50,9 @@ const (
 )
 
 type ModuleInfo struct {
-	Version   string
-	Name      string
-	Factories []string
+	Version   string   `json:"version"`
+	Name      string   `json:"name"`
+	Factories []string `json:"factories"`
 }
 
 // Type of media stream to be exchanged.
@@ -80,6 +85,24 @@ const (
 	FILTERTYPE_VIDEO      FilterType = "VIDEO"
 )
 
+// Details of the GStreamer graph returned by GetGstreamerDot
+type GstreamerDotDetails string
+
+// Implement fmt.Stringer interface
+func (t GstreamerDotDetails) String() string {
+	return string(t)
+}
+
+const (
+	GSTREAMERDOTDETAILS_SHOW_MEDIA_TYPE         GstreamerDotDetails = "SHOW_MEDIA_TYPE"
+	GSTREAMERDOTDETAILS_SHOW_CAPS_DETAILS       GstreamerDotDetails = "SHOW_CAPS_DETAILS"
+	GSTREAMERDOTDETAILS_SHOW_NON_DEFAULT_PARAMS GstreamerDotDetails = "SHOW_NON_DEFAULT_PARAMS"
+	GSTREAMERDOTDETAILS_SHOW_STATES             GstreamerDotDetails = "SHOW_STATES"
+	GSTREAMERDOTDETAILS_SHOW_FULL_PARAMS        GstreamerDotDetails = "SHOW_FULL_PARAMS"
+	GSTREAMERDOTDETAILS_SHOW_ALL                GstreamerDotDetails = "SHOW_ALL"
+	GSTREAMERDOTDETAILS_SHOW_VERBOSE            GstreamerDotDetails = "SHOW_VERBOSE"
+)
+
 // Codec used for transmission of video.
 type VideoCodec string
 
@@ -90,10 +113,25 @@ func (t VideoCodec) String() string {
 
 const (
 	VIDEOCODEC_VP8  VideoCodec = "VP8"
+	VIDEOCODEC_VP9  VideoCodec = "VP9"
 	VIDEOCODEC_H264 VideoCodec = "H264"
+	VIDEOCODEC_H265 VideoCodec = "H265"
 	VIDEOCODEC_RAW  VideoCodec = "RAW"
 )
 
+// Implement encoding.TextUnmarshaler interface. Known codec names are not
+// case sensitive, other names are kept as is.
+func (t *VideoCodec) UnmarshalText(text []byte) error {
+	codec := VideoCodec(strings.ToUpper(string(text)))
+	switch codec {
+	case VIDEOCODEC_VP8, VIDEOCODEC_VP9, VIDEOCODEC_H264, VIDEOCODEC_H265, VIDEOCODEC_RAW:
+		*t = codec
+	default:
+		*t = VideoCodec(text)
+	}
+	return nil
+}
+
 // Codec used for transmission of audio.
 type AudioCodec string
 
@@ -105,22 +143,38 @@ func (t AudioCodec) String() string {
 const (
 	AUDIOCODEC_OPUS AudioCodec = "OPUS"
 	AUDIOCODEC_PCMU AudioCodec = "PCMU"
+	AUDIOCODEC_PCMA AudioCodec = "PCMA"
+	AUDIOCODEC_G722 AudioCodec = "G722"
+	AUDIOCODEC_AMR  AudioCodec = "AMR"
 	AUDIOCODEC_RAW  AudioCodec = "RAW"
 )
 
+// Implement encoding.TextUnmarshaler interface. Known codec names are not
+// case sensitive, other names are kept as is.
+func (t *AudioCodec) UnmarshalText(text []byte) error {
+	codec := AudioCodec(strings.ToUpper(string(text)))
+	switch codec {
+	case AUDIOCODEC_OPUS, AUDIOCODEC_PCMU, AUDIOCODEC_PCMA, AUDIOCODEC_G722, AUDIOCODEC_AMR, AUDIOCODEC_RAW:
+		*t = codec
+	default:
+		*t = AudioCodec(text)
+	}
+	return nil
+}
+
 type Fraction struct {
-	Numerator   int
-	Denominator int
+	Numerator   int `json:"numerator"`
+	Denominator int `json:"denominator"`
 }
 
 type AudioCaps struct {
-	Codec   AudioCodec
-	Bitrate int
+	Codec   AudioCodec `json:"codec"`
+	Bitrate int        `json:"bitrate"`
 }
 
 type VideoCaps struct {
-	Codec     VideoCodec
-	Framerate Fraction
+	Codec     VideoCodec `json:"codec"`
+	Framerate Fraction   `json:"framerate"`
 }
 
 type ElementConnectionData struct {
@@ -130,3 +184,235 @@ type ElementConnectionData struct {
 	SourceDescription string
 	SinkDescription   string
 }
+
+// Wire format of ElementConnectionData, elements are given by id
+type elementConnectionJSON struct {
+	Source            string    `json:"source"`
+	Sink              string    `json:"sink"`
+	Type              MediaType `json:"type"`
+	SourceDescription string    `json:"sourceDescription"`
+	SinkDescription   string    `json:"sinkDescription"`
+}
+
+// Implement json.Marshaler interface
+func (d ElementConnectionData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(elementConnectionJSON{
+		Source:            d.Source.Id,
+		Sink:              d.Sink.Id,
+		Type:              d.Type,
+		SourceDescription: d.SourceDescription,
+		SinkDescription:   d.SinkDescription,
+	})
+}
+
+// Implement json.Unmarshaler interface. Only the ids of Source and Sink are
+// set.
+func (d *ElementConnectionData) UnmarshalJSON(data []byte) error {
+	v := elementConnectionJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	d.Source.Id = v.Source
+	d.Sink.Id = v.Sink
+	d.Type = v.Type
+	d.SourceDescription = v.SourceDescription
+	d.SinkDescription = v.SinkDescription
+	return nil
+}
+
+// Parameter representing a window in a video stream. It is used in command and
+// constructor for media elements.
+type WindowParam struct {
+	TopRightCornerX int `json:"topRightCornerX"`
+	TopRightCornerY int `json:"topRightCornerY"`
+	Width           int `json:"width"`
+	Height          int `json:"height"`
+}
+
+// Point in a video frame, coordinates are relative to the frame size, from 0 to
+// 1
+type RelativePoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Region of interest of a `CrowdDetectorFilter`
+type RegionOfInterest struct {
+	Points                 []RelativePoint        `json:"points"`
+	RegionOfInterestConfig RegionOfInterestConfig `json:"regionOfInterestConfig"`
+	Id                     string                 `json:"id"`
+}
+
+// Detection levels of a `RegionOfInterest`, see
+// DefaultRegionOfInterestConfig for the values used by the media server.
+type RegionOfInterestConfig struct {
+	OccupancyLevelMin           int  `json:"occupancyLevelMin"`
+	OccupancyLevelMed           int  `json:"occupancyLevelMed"`
+	OccupancyLevelMax           int  `json:"occupancyLevelMax"`
+	OccupancyNumFramesToEvent   int  `json:"occupancyNumFramesToEvent"`
+	FluidityLevelMin            int  `json:"fluidityLevelMin"`
+	FluidityLevelMed            int  `json:"fluidityLevelMed"`
+	FluidityLevelMax            int  `json:"fluidityLevelMax"`
+	FluidityNumFramesToEvent    int  `json:"fluidityNumFramesToEvent"`
+	SendOpticalFlowEvent        bool `json:"sendOpticalFlowEvent"`
+	OpticalFlowNumFramesToEvent int  `json:"opticalFlowNumFramesToEvent"`
+	OpticalFlowNumFramesToReset int  `json:"opticalFlowNumFramesToReset"`
+	OpticalFlowAngleOffset      int  `json:"opticalFlowAngleOffset"`
+}
+
+// Default values of RegionOfInterestConfig in the media server
+var DefaultRegionOfInterestConfig = RegionOfInterestConfig{
+	OccupancyLevelMin:           10,
+	OccupancyLevelMed:           35,
+	OccupancyLevelMax:           65,
+	OccupancyNumFramesToEvent:   5,
+	FluidityLevelMin:            10,
+	FluidityLevelMed:            35,
+	FluidityLevelMax:            65,
+	FluidityNumFramesToEvent:    5,
+	OpticalFlowNumFramesToEvent: 3,
+	OpticalFlowNumFramesToReset: 3,
+}
+
+// Window of a `PointerDetectorFilter`, the filter raises "WindowIn" and
+// "WindowOut" events when the pointer enters or leaves it.
+type PointerDetectorWindowMediaParam struct {
+	Id                string  `json:"id"`
+	Height            int     `json:"height"`
+	Width             int     `json:"width"`
+	UpperRightX       int     `json:"upperRightX"`
+	UpperRightY       int     `json:"upperRightY"`
+	ActiveImage       string  `json:"activeImage,omitempty"`
+	ImageTransparency float64 `json:"imageTransparency,omitempty"`
+	Image             string  `json:"image,omitempty"`
+	InactiveImage     string  `json:"inactiveImage,omitempty"`
+}
+
+// Describes the encryption and authentication algorithms of SRTP
+type CryptoSuite string
+
+// Implement fmt.Stringer interface
+func (t CryptoSuite) String() string {
+	return string(t)
+}
+
+const (
+	CRYPTOSUITE_AES_128_CM_HMAC_SHA1_32 CryptoSuite = "AES_128_CM_HMAC_SHA1_32"
+	CRYPTOSUITE_AES_128_CM_HMAC_SHA1_80 CryptoSuite = "AES_128_CM_HMAC_SHA1_80"
+	CRYPTOSUITE_AES_256_CM_HMAC_SHA1_32 CryptoSuite = "AES_256_CM_HMAC_SHA1_32"
+	CRYPTOSUITE_AES_256_CM_HMAC_SHA1_80 CryptoSuite = "AES_256_CM_HMAC_SHA1_80"
+)
+
+// Security Descriptions for Media Streams, the SRTP configuration of an
+// `RtpEndpoint`. Only one of Key and KeyBase64 must be set, see NewSDES.
+type SDES struct {
+	// Master key and salt, 30 characters for AES_128 suites, 46 for AES_256
+	Key string `json:"key,omitempty"`
+
+	// Master key and salt encoded in base64, 30 or 46 bytes once decoded
+	KeyBase64 string `json:"keyBase64,omitempty"`
+
+	// Crypto suite used
+	CryptoSuite CryptoSuite `json:"crypto"`
+}
+
+// Information about the media played by a `PlayerEndpoint`
+type VideoInfo struct {
+	// Seek is possible in the video source
+	IsSeekable bool `json:"isSeekable"`
+
+	// First video position to do seek, in milliseconds
+	SeekableInit int64 `json:"seekableInit"`
+
+	// Last video position to do seek, in milliseconds
+	SeekableEnd int64 `json:"seekableEnd"`
+
+	// Video duration, in milliseconds
+	Duration int64 `json:"duration"`
+}
+
+// Flowing state of the media of a pad, given by the MediaFlowInStateChange
+// and MediaFlowOutStateChange events. Only available in KMS 6.
+// Can take the values FLOWING or NOT_FLOWING.
+type MediaFlowState string
+
+// Implement fmt.Stringer interface
+func (t MediaFlowState) String() string {
+	return string(t)
+}
+
+const (
+	MEDIAFLOWSTATE_FLOWING     MediaFlowState = "FLOWING"
+	MEDIAFLOWSTATE_NOT_FLOWING MediaFlowState = "NOT_FLOWING"
+)
+
+// State of the connection of a `BaseRtpEndpoint`. Only available in KMS 6.
+// Can take the values DISCONNECTED or CONNECTED.
+type ConnectionState string
+
+// Implement fmt.Stringer interface
+func (t ConnectionState) String() string {
+	return string(t)
+}
+
+const (
+	CONNECTIONSTATE_DISCONNECTED ConnectionState = "DISCONNECTED"
+	CONNECTIONSTATE_CONNECTED    ConnectionState = "CONNECTED"
+)
+
+// State of the media of a `BaseRtpEndpoint`, CONNECTED when RTCP packets are
+// received. Only available in KMS 6.
+// Can take the values DISCONNECTED or CONNECTED.
+type MediaState string
+
+// Implement fmt.Stringer interface
+func (t MediaState) String() string {
+	return string(t)
+}
+
+const (
+	MEDIASTATE_DISCONNECTED MediaState = "DISCONNECTED"
+	MEDIASTATE_CONNECTED    MediaState = "CONNECTED"
+)
+
+// State of an ICE component of a `WebRtcEndpoint`. Only available in KMS 6.
+// Can take the values DISCONNECTED, GATHERING, CONNECTING, CONNECTED, READY
+// or FAILED.
+type IceComponentState string
+
+// Implement fmt.Stringer interface
+func (t IceComponentState) String() string {
+	return string(t)
+}
+
+const (
+	ICECOMPONENTSTATE_DISCONNECTED IceComponentState = "DISCONNECTED"
+	ICECOMPONENTSTATE_GATHERING    IceComponentState = "GATHERING"
+	ICECOMPONENTSTATE_CONNECTING   IceComponentState = "CONNECTING"
+	ICECOMPONENTSTATE_CONNECTED    IceComponentState = "CONNECTED"
+	ICECOMPONENTSTATE_READY        IceComponentState = "READY"
+	ICECOMPONENTSTATE_FAILED       IceComponentState = "FAILED"
+)
+
+// Type of key of the DTLS certificate of a `WebRtcEndpoint`. Only available
+// in KMS 6.
+// Can take the values RSA or ECDSA.
+type CertificateKeyType string
+
+// Implement fmt.Stringer interface
+func (t CertificateKeyType) String() string {
+	return string(t)
+}
+
+const (
+	CERTIFICATEKEYTYPE_RSA   CertificateKeyType = "RSA"
+	CERTIFICATEKEYTYPE_ECDSA CertificateKeyType = "ECDSA"
+)
+
+// Pair of ICE candidates selected by a `WebRtcEndpoint`
+type IceCandidatePair struct {
+	StreamId        string `json:"streamID"`
+	ComponentId     int    `json:"componentID"`
+	LocalCandidate  string `json:"localCandidate"`
+	RemoteCandidate string `json:"remoteCandidate"`
+}
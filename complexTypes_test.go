@@ -0,0 +1,178 @@
+package kurento
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestComplexTypesJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		// pointer to a zero value to decode the golden JSON into
+		decoded interface{}
+		golden  string
+	}{
+		{
+			"IceCandidate",
+			IceCandidate{
+				Candidate:     "candidate:1 1 UDP 2122252543 192.168.1.2 56143 typ host",
+				SdpMid:        "0",
+				SdpMLineIndex: 0,
+			},
+			&IceCandidate{},
+			`{"candidate":"candidate:1 1 UDP 2122252543 192.168.1.2 56143 typ host","sdpMid":"0","sdpMLineIndex":0}`,
+		},
+		{
+			"AudioCaps",
+			AudioCaps{Codec: AUDIOCODEC_OPUS, Bitrate: 32000},
+			&AudioCaps{},
+			`{"codec":"OPUS","bitrate":32000}`,
+		},
+		{
+			"VideoCaps",
+			VideoCaps{Codec: VIDEOCODEC_VP8, Framerate: Fraction{Numerator: 30000, Denominator: 1001}},
+			&VideoCaps{},
+			`{"codec":"VP8","framerate":{"numerator":30000,"denominator":1001}}`,
+		},
+		{
+			"Fraction",
+			Fraction{Numerator: 15, Denominator: 1},
+			&Fraction{},
+			`{"numerator":15,"denominator":1}`,
+		},
+		{
+			"SDES",
+			SDES{KeyBase64: "MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkw", CryptoSuite: CRYPTOSUITE_AES_128_CM_HMAC_SHA1_80},
+			&SDES{},
+			`{"keyBase64":"MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkw","crypto":"AES_128_CM_HMAC_SHA1_80"}`,
+		},
+		{
+			"WindowParam",
+			WindowParam{TopRightCornerX: 0, TopRightCornerY: 10, Width: 320, Height: 240},
+			&WindowParam{},
+			`{"topRightCornerX":0,"topRightCornerY":10,"width":320,"height":240}`,
+		},
+		{
+			"RegionOfInterest",
+			RegionOfInterest{
+				Points:                 []RelativePoint{{0, 0}, {0.5, 0}, {0.5, 0.25}},
+				RegionOfInterestConfig: DefaultRegionOfInterestConfig,
+				Id:                     "roi0",
+			},
+			&RegionOfInterest{},
+			`{"points":[{"x":0,"y":0},{"x":0.5,"y":0},{"x":0.5,"y":0.25}],` +
+				`"regionOfInterestConfig":{"occupancyLevelMin":10,"occupancyLevelMed":35,"occupancyLevelMax":65,` +
+				`"occupancyNumFramesToEvent":5,"fluidityLevelMin":10,"fluidityLevelMed":35,"fluidityLevelMax":65,` +
+				`"fluidityNumFramesToEvent":5,"sendOpticalFlowEvent":false,"opticalFlowNumFramesToEvent":3,` +
+				`"opticalFlowNumFramesToReset":3,"opticalFlowAngleOffset":0},"id":"roi0"}`,
+		},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.value)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(b) != tt.golden {
+			t.Errorf("%s is encoded as\n%s\nwant\n%s", tt.name, b, tt.golden)
+		}
+
+		if err := json.Unmarshal([]byte(tt.golden), tt.decoded); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.decoded).Elem().Interface(); !reflect.DeepEqual(got, tt.value) {
+			t.Errorf("%s is decoded as %#v, want %#v", tt.name, got, tt.value)
+		}
+	}
+}
+
+func TestElementConnectionDataJSON(t *testing.T) {
+	golden := `{"source":"pipeline/src","sink":"pipeline/sink","type":"VIDEO","sourceDescription":"default","sinkDescription":""}`
+
+	d := ElementConnectionData{Type: MEDIATYPE_VIDEO, SourceDescription: "default"}
+	d.Source.Id = "pipeline/src"
+	d.Sink.Id = "pipeline/sink"
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != golden {
+		t.Errorf("ElementConnectionData is encoded as\n%s\nwant\n%s", b, golden)
+	}
+
+	decoded := ElementConnectionData{}
+	if err := json.Unmarshal([]byte(golden), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Source.Id != "pipeline/src" || decoded.Sink.Id != "pipeline/sink" ||
+		decoded.Type != MEDIATYPE_VIDEO || decoded.SourceDescription != "default" || decoded.SinkDescription != "" {
+		t.Errorf("ElementConnectionData is decoded as %+v", decoded)
+	}
+}
+
+func TestSetIfNotEmpty(t *testing.T) {
+	candidate := &IceCandidate{Candidate: "candidate:1"}
+	tests := []struct {
+		name  string
+		value interface{}
+		set   bool
+	}{
+		{"zero struct", IceCandidate{}, false},
+		{"struct with a zero index", IceCandidate{Candidate: "candidate:1", SdpMLineIndex: 0}, true},
+		{"nil pointer", (*IceCandidate)(nil), false},
+		{"pointer", candidate, true},
+		{"nil slice", []RegionOfInterest(nil), false},
+		{"empty slice", []RegionOfInterest{}, false},
+		{"slice", []RegionOfInterest{{Id: "roi0"}}, true},
+		{"empty map", map[string]string{}, false},
+		{"zero int64", int64(0), false},
+		{"int64", int64(1500), true},
+		{"zero uint32", uint32(0), false},
+		{"zero int", 0, false},
+		{"int", 1, true},
+		{"zero float64", 0.0, false},
+		{"float64", 0.5, true},
+		{"float32", float32(0.5), true},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		params := map[string]interface{}{}
+		setIfNotEmpty(params, "value", tt.value)
+		v, ok := params["value"]
+		if ok != tt.set {
+			t.Errorf("%s: set is %v, want %v", tt.name, ok, tt.set)
+			continue
+		}
+		if ok && !reflect.DeepEqual(v, tt.value) {
+			t.Errorf("%s: value is %#v, want %#v", tt.name, v, tt.value)
+		}
+	}
+}
+
+func TestAddIceCandidateParams(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	candidate := IceCandidate{Candidate: "candidate:1 1 UDP 2122252543 192.168.1.2 56143 typ host", SdpMid: "0"}
+	if err := webrtc.AddIceCandidate(candidate); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"candidate":     candidate.Candidate,
+		"sdpMid":        "0",
+		"sdpMLineIndex": 0.0,
+	}
+	if got := operationParamsOf(f.last())["candidate"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidate is sent as %v, want %v", got, want)
+	}
+}
@@ -1,11 +1,20 @@
 package kurento
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
 
 // Base for all objects that can be created in the media server.
 type MediaObject struct {
 	connection *Connection
 
+	// connection graph of a pipeline, see MediaPipeline.TrackConnections
+	graph *ConnectionGraph
+
 	// `MediaPipeline` to which this MediaObject belong, or the pipeline itself if
 	// invoked over a `MediaPipeline`
 	MediaPipeline IMediaPipeline
@@ -36,6 +45,7 @@ func (elem *MediaObject) getConstructorParams(from IMediaObject, options map[str
 }
 
 type IServerManager interface {
+	GetInfo() (*ServerInfo, error)
 }
 
 // This is a standalone object for managing the MediaServer
@@ -58,7 +68,33 @@ func (elem *ServerManager) getConstructorParams(from IMediaObject, options map[s
 
 }
 
+// Server information, version, modules, factories, etc
+// Returns:
+// // The server information
+func (elem *ServerManager) GetInfo() (*ServerInfo, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getInfo",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The server information
+
+	ret := &ServerInfo{}
+	if err := response.decodeValue(ret); err != nil {
+		return nil, err
+	}
+	elem.Info = ret
+	return ret, nil
+
+}
+
 type ISessionEndpoint interface {
+	ReleaseOnTerminate() error
 }
 
 // Session based endpoint. A session is considered to be started when the media
@@ -74,13 +110,30 @@ func (elem *SessionEndpoint) getConstructorParams(from IMediaObject, options map
 
 }
 
+// Release the endpoint when the server raises "MediaSessionTerminated", e.g.
+// when the client of an HttpGetEndpoint disconnects.
+func (elem *SessionEndpoint) ReleaseOnTerminate() error {
+	_, err := elem.Subscribe("MediaSessionTerminated", func(Event) {
+		if err := elem.Release(); err != nil && debug {
+			log.Println("Cannot release", elem.Id, err)
+		}
+	})
+	return err
+}
+
 type IHub interface {
+	CreatePort(ctx context.Context) (*HubPort, error)
+	Ports() []*HubPort
 }
 
 // A Hub is a routing `MediaObject`. It connects several `endpoints <Endpoint>`
 // together
 type Hub struct {
 	MediaObject
+
+	// ports created with CreatePort
+	mu    sync.Mutex
+	ports []*HubPort
 }
 
 // Return contructor params to be called by "Create".
@@ -89,6 +142,69 @@ func (elem *Hub) getConstructorParams(from IMediaObject, options map[string]inte
 
 }
 
+// Create a new `HubPort` of the hub. If ctx is done before the server
+// answers, the port is released as soon as it is created.
+func (elem *Hub) CreatePort(ctx context.Context) (*HubPort, error) {
+	port := &HubPort{hub: elem}
+
+	created := make(chan error, 1)
+	go func() {
+		created <- elem.Create(port, nil)
+	}()
+
+	select {
+	case err := <-created:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		go func() {
+			if err := <-created; err == nil {
+				port.MediaElement.Release()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+
+	elem.mu.Lock()
+	elem.ports = append(elem.ports, port)
+	elem.mu.Unlock()
+	return port, nil
+}
+
+// Ports returns the ports created with CreatePort that are not released.
+func (elem *Hub) Ports() []*HubPort {
+	elem.mu.Lock()
+	defer elem.mu.Unlock()
+	return append([]*HubPort{}, elem.ports...)
+}
+
+// Release the ports created with CreatePort, then the hub.
+func (elem *Hub) Release() error {
+	var ret error
+	for _, port := range elem.Ports() {
+		if err := port.Release(); err != nil && ret == nil {
+			ret = err
+		}
+	}
+	if err := elem.MediaObject.Release(); err != nil {
+		return err
+	}
+	return ret
+}
+
+// Remove a released port
+func (elem *Hub) removePort(port *HubPort) {
+	elem.mu.Lock()
+	defer elem.mu.Unlock()
+	for i, p := range elem.ports {
+		if p == port {
+			elem.ports = append(elem.ports[:i], elem.ports[i+1:]...)
+			return
+		}
+	}
+}
+
 type IFilter interface {
 }
 
@@ -130,6 +246,9 @@ type IHubPort interface {
 // This `MediaElement` specifies a connection with a `Hub`
 type HubPort struct {
 	MediaElement
+
+	// hub that created the port with CreatePort
+	hub *Hub
 }
 
 // Return contructor params to be called by "Create".
@@ -147,6 +266,14 @@ func (elem *HubPort) getConstructorParams(from IMediaObject, options map[string]
 
 }
 
+// Release the port, and remove it from the ports of its hub.
+func (elem *HubPort) Release() error {
+	if elem.hub != nil {
+		elem.hub.removePort(elem)
+	}
+	return elem.MediaElement.Release()
+}
+
 type IPassThrough interface {
 }
 
@@ -203,7 +330,7 @@ func (elem *UriEndpoint) Pause() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -220,11 +347,12 @@ func (elem *UriEndpoint) Stop() error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
 type IMediaPipeline interface {
+	GetGstreamerDot(details GstreamerDotDetails) (string, error)
 }
 
 // A pipeline is a container for a collection of `MediaElements<MediaElement>` and
@@ -240,12 +368,21 @@ func (elem *MediaPipeline) getConstructorParams(from IMediaObject, options map[s
 
 }
 
+// Returns a string in dot (graphviz) format that represents the gstreamer
+// elements inside the pipeline
+// Returns:
+// // The dot graph
+func (elem *MediaPipeline) GetGstreamerDot(details GstreamerDotDetails) (string, error) {
+	return getGstreamerDot(&elem.MediaObject, details)
+}
+
 type ISdpEndpoint interface {
 	GenerateOffer() (string, error)
 	ProcessOffer(offer string) (string, error)
 	ProcessAnswer(answer string) (string, error)
 	GetLocalSessionDescriptor() (string, error)
 	GetRemoteSessionDescriptor() (string, error)
+	SetMaxVideoRecvBandwidth(maxVideoRecvBandwidth int) error
 }
 
 // Implements an SDP negotiation endpoint able to generate and process
@@ -259,6 +396,10 @@ type SdpEndpoint struct {
 	// 0: unlimited.
 	// Default value: 500
 	MaxVideoRecvBandwidth int
+
+	// Codecs of the offers given to GenerateOffer and ProcessOffer, and of
+	// the SDP they return
+	CodecPreferences CodecPreferences
 }
 
 // Return contructor params to be called by "Create".
@@ -284,7 +425,10 @@ func (elem *SdpEndpoint) GenerateOffer() (string, error) {
 
 	// // The SDP offer.
 
-	return response.Result["value"], response.Error
+	if err := response.Err(); err != nil {
+		return "", err
+	}
+	return ApplyCodecPreferences(response.Result["value"], elem.CodecPreferences)
 
 }
 
@@ -293,6 +437,11 @@ func (elem *SdpEndpoint) GenerateOffer() (string, error) {
 // Returns:
 // // The chosen configuration from the ones stated in the SDP offer
 func (elem *SdpEndpoint) ProcessOffer(offer string) (string, error) {
+	offer, err := ApplyCodecPreferences(offer, elem.CodecPreferences)
+	if err != nil {
+		return "", err
+	}
+
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -310,7 +459,10 @@ func (elem *SdpEndpoint) ProcessOffer(offer string) (string, error) {
 
 	// // The chosen configuration from the ones stated in the SDP offer
 
-	return response.Result["value"], response.Error
+	if err := response.Err(); err != nil {
+		return "", err
+	}
+	return ApplyCodecPreferences(response.Result["value"], elem.CodecPreferences)
 
 }
 
@@ -336,7 +488,7 @@ func (elem *SdpEndpoint) ProcessAnswer(answer string) (string, error) {
 
 	// // Updated SDP offer, based on the answer received.
 
-	return response.Result["value"], response.Error
+	return response.Result["value"], response.Err()
 
 }
 
@@ -360,7 +512,7 @@ func (elem *SdpEndpoint) GetLocalSessionDescriptor() (string, error) {
 
 	// // The last agreed SessionSpec
 
-	return response.Result["value"], response.Error
+	return response.Result["value"], response.Err()
 
 }
 
@@ -382,11 +534,25 @@ func (elem *SdpEndpoint) GetRemoteSessionDescriptor() (string, error) {
 
 	// // The last agreed User Agent session description
 
-	return response.Result["value"], response.Error
+	return response.Result["value"], response.Err()
+
+}
 
+// Sets the maximum video bandwidth for receiving, in kbps. 0 is unlimited.
+func (elem *SdpEndpoint) SetMaxVideoRecvBandwidth(maxVideoRecvBandwidth int) error {
+	if err := elem.setProperty("setMaxVideoRecvBandwidth", "maxVideoRecvBandwidth", maxVideoRecvBandwidth); err != nil {
+		return err
+	}
+	elem.MaxVideoRecvBandwidth = maxVideoRecvBandwidth
+	return nil
 }
 
 type IBaseRtpEndpoint interface {
+	SetMinVideoSendBandwidth(minVideoSendBandwidth int) error
+	SetMaxVideoSendBandwidth(maxVideoSendBandwidth int) error
+	ApplyBandwidthProfile(profile BandwidthProfile) error
+	GetConnectionState() (ConnectionState, error)
+	GetMediaState() (MediaState, error)
 }
 
 // Base class to manage common RTP features.
@@ -412,13 +578,82 @@ func (elem *BaseRtpEndpoint) getConstructorParams(from IMediaObject, options map
 
 }
 
+// Sets the minimum video bandwidth for sending, in kbps. 0 is unlimited.
+func (elem *BaseRtpEndpoint) SetMinVideoSendBandwidth(minVideoSendBandwidth int) error {
+	if err := elem.setProperty("setMinVideoSendBandwidth", "minVideoSendBandwidth", minVideoSendBandwidth); err != nil {
+		return err
+	}
+	elem.MinVideoSendBandwidth = minVideoSendBandwidth
+	return nil
+}
+
+// Sets the maximum video bandwidth for sending, in kbps. 0 is unlimited.
+func (elem *BaseRtpEndpoint) SetMaxVideoSendBandwidth(maxVideoSendBandwidth int) error {
+	if err := elem.setProperty("setMaxVideoSendBandwidth", "maxVideoSendBandwidth", maxVideoSendBandwidth); err != nil {
+		return err
+	}
+	elem.MaxVideoSendBandwidth = maxVideoSendBandwidth
+	return nil
+}
+
+// Returns the state of the connection. Only available in KMS 6.
+func (elem *BaseRtpEndpoint) GetConnectionState() (ConnectionState, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getConnectionState",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The state of the connection
+
+	var state ConnectionState
+	err := response.decodeValue(&state)
+	return state, err
+
+}
+
+// Returns the state of the media, CONNECTED when RTCP packets are received.
+// Only available in KMS 6.
+func (elem *BaseRtpEndpoint) GetMediaState() (MediaState, error) {
+	req := elem.getInvokeRequest()
+
+	req["params"] = map[string]interface{}{
+		"operation": "getMediaState",
+		"object":    elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The state of the media
+
+	var state MediaState
+	err := response.decodeValue(&state)
+	return state, err
+
+}
+
 type IMediaElement interface {
+	GetMediaSrcs(mediaType MediaType, description string) ([]*MediaSource, error)
+	GetMediaSinks(mediaType MediaType, description string) ([]*MediaSink, error)
+	GetGstreamerDot(details GstreamerDotDetails) (string, error)
 	GetSourceConnections(mediaType MediaType, description string) ([]ElementConnectionData, error)
 	GetSinkConnections(mediaType MediaType, description string) ([]ElementConnectionData, error)
 	Connect(sink IMediaElement, mediaType MediaType, sourceMediaDescription string, sinkMediaDescription string) error
 	Disconnect(sink IMediaElement, mediaType MediaType, sourceMediaDescription string, sinkMediaDescription string) error
 	SetAudioFormat(caps AudioCaps) error
 	SetVideoFormat(caps VideoCaps) error
+	GetStats(mediaType MediaType) (map[string]Stats, error)
+	SetMinOutputBitrate(minOutputBitrate int) error
+	SetMaxOutputBitrate(maxOutputBitrate int) error
+	SetMinEncoderBitrate(minEncoderBitrate int) error
+	SetMaxEncoderBitrate(maxEncoderBitrate int) error
+	IsMediaFlowingIn(mediaType MediaType, sinkMediaDescription string) (bool, error)
+	IsMediaFlowingOut(mediaType MediaType, sourceMediaDescription string) (bool, error)
 }
 
 // Basic building blocks of the media server, that can be interconnected through
@@ -438,6 +673,65 @@ func (elem *MediaElement) getConstructorParams(from IMediaObject, options map[st
 
 }
 
+// Get the `MediaSources<MediaSource>` of this element, filtered by media type
+// and description if they are not empty.
+// Returns:
+// // A list of sources. The list will be empty if no sources are found.
+func (elem *MediaElement) GetMediaSrcs(mediaType MediaType, description string) ([]*MediaSource, error) {
+	ids, err := elem.getMediaPads("getMediaSrcs", mediaType, description)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*MediaSource{}
+	for _, id := range ids {
+		src := &MediaSource{}
+		src.init(elem, id, mediaType, description)
+		ret = append(ret, src)
+	}
+	return ret, nil
+}
+
+// Get the `MediaSinks<MediaSink>` of this element, filtered by media type and
+// description if they are not empty.
+// Returns:
+// // A list of sinks. The list will be empty if no sinks are found.
+func (elem *MediaElement) GetMediaSinks(mediaType MediaType, description string) ([]*MediaSink, error) {
+	ids, err := elem.getMediaPads("getMediaSinks", mediaType, description)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*MediaSink{}
+	for _, id := range ids {
+		sink := &MediaSink{}
+		sink.init(elem, id, mediaType, description)
+		ret = append(ret, sink)
+	}
+	return ret, nil
+}
+
+// Return pad ids given by "getMediaSrcs" or "getMediaSinks"
+func (elem *MediaElement) getMediaPads(operation string, mediaType MediaType, description string) ([]string, error) {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "mediaType", mediaType)
+	setIfNotEmpty(params, "description", description)
+
+	req["params"] = map[string]interface{}{
+		"operation":       operation,
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	ids := []string{}
+	err := response.decodeValue(&ids)
+	return ids, err
+}
+
 // Get the connections information of the elements that are sending media to this
 // element `MediaElement`
 // Returns:
@@ -466,7 +760,14 @@ func (elem *MediaElement) GetSourceConnections(mediaType MediaType, description
 	// // The list will be empty if no sources are found.
 
 	ret := []ElementConnectionData{}
-	return ret, response.Error
+	if err := response.decodeValue(&ret); err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		ret[i].Source.setConnection(elem.connection)
+		ret[i].Sink.setConnection(elem.connection)
+	}
+	return ret, nil
 
 }
 
@@ -496,7 +797,14 @@ func (elem *MediaElement) GetSinkConnections(mediaType MediaType, description st
 	// // element. The list will be empty if no sinks are found.
 
 	ret := []ElementConnectionData{}
-	return ret, response.Error
+	if err := response.decodeValue(&ret); err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		ret[i].Source.setConnection(elem.connection)
+		ret[i].Sink.setConnection(elem.connection)
+	}
+	return ret, nil
 
 }
 
@@ -524,7 +832,7 @@ func (elem *MediaElement) Connect(sink IMediaElement, mediaType MediaType, sourc
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -551,7 +859,7 @@ func (elem *MediaElement) Disconnect(sink IMediaElement, mediaType MediaType, so
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -574,7 +882,7 @@ func (elem *MediaElement) SetAudioFormat(caps AudioCaps) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
 
 }
 
@@ -597,6 +905,133 @@ func (elem *MediaElement) SetVideoFormat(caps VideoCaps) error {
 	response := <-elem.connection.Request(req)
 
 	// Returns error or nil
-	return response.Error
+	return response.Err()
+
+}
+
+// Gets the statistics related to an endpoint. If no media type is specified,
+// it returns statistics for all available types.
+// Returns:
+// // Delivers a successful result in the form of a RTC stats report. A RTC
+// // stats report represents a map between strings, identifying the inspected
+// // objects (RTCStats.id), and their corresponding RTCStats objects.
+func (elem *MediaElement) GetStats(mediaType MediaType) (map[string]Stats, error) {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "mediaType", mediaType)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "getStats",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // Delivers a successful result in the form of a RTC stats report.
+
+	raw := map[string]json.RawMessage{}
+	if err := response.decodeValue(&raw); err != nil {
+		return nil, err
+	}
+	return decodeStats(raw), nil
+
+}
+
+// Sets the minimum output bitrate of the element, in bps.
+func (elem *MediaElement) SetMinOutputBitrate(minOutputBitrate int) error {
+	return elem.setProperty("setMinOutputBitrate", "minOutputBitrate", minOutputBitrate)
+}
+
+// Sets the maximum output bitrate of the element, in bps. 0 is unlimited.
+func (elem *MediaElement) SetMaxOutputBitrate(maxOutputBitrate int) error {
+	return elem.setProperty("setMaxOutputBitrate", "maxOutputBitrate", maxOutputBitrate)
+}
+
+// Sets the minimum bitrate of the video encoder, in kbps. Replaces
+// SetMinOutputBitrate in recent media servers.
+func (elem *MediaElement) SetMinEncoderBitrate(minEncoderBitrate int) error {
+	return elem.setProperty("setMinEncoderBitrate", "minEncoderBitrate", minEncoderBitrate)
+}
+
+// Sets the maximum bitrate of the video encoder, in kbps. 0 is unlimited.
+// Replaces SetMaxOutputBitrate in recent media servers.
+func (elem *MediaElement) SetMaxEncoderBitrate(maxEncoderBitrate int) error {
+	return elem.setProperty("setMaxEncoderBitrate", "maxEncoderBitrate", maxEncoderBitrate)
+}
+
+// Check if media is flowing into the sink pads of the given type and
+// description, if not empty. Only available in KMS 6.
+// Returns:
+// // TRUE if there is media, FALSE in other case
+func (elem *MediaElement) IsMediaFlowingIn(mediaType MediaType, sinkMediaDescription string) (bool, error) {
+	return elem.isMediaFlowing("isMediaFlowingIn", "sinkMediaDescription", mediaType, sinkMediaDescription)
+}
+
+// Check if media is flowing out of the source pads of the given type and
+// description, if not empty. Only available in KMS 6.
+// Returns:
+// // TRUE if there is media, FALSE in other case
+func (elem *MediaElement) IsMediaFlowingOut(mediaType MediaType, sourceMediaDescription string) (bool, error) {
+	return elem.isMediaFlowing("isMediaFlowingOut", "sourceMediaDescription", mediaType, sourceMediaDescription)
+}
+
+// Shared by IsMediaFlowingIn and IsMediaFlowingOut
+func (elem *MediaElement) isMediaFlowing(operation string, descriptionParam string, mediaType MediaType, description string) (bool, error) {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "mediaType", mediaType)
+	setIfNotEmpty(params, descriptionParam, description)
+
+	req["params"] = map[string]interface{}{
+		"operation":       operation,
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // TRUE if there is media, FALSE in other case
+
+	var flowing bool
+	err := response.decodeValue(&flowing)
+	return flowing, err
+
+}
+
+// Returns a string in dot (graphviz) format that represents the gstreamer
+// elements inside this media element
+// Returns:
+// // The dot graph
+func (elem *MediaElement) GetGstreamerDot(details GstreamerDotDetails) (string, error) {
+	return getGstreamerDot(&elem.MediaObject, details)
+}
+
+// Shared by MediaPipeline and MediaElement
+func getGstreamerDot(elem *MediaObject, details GstreamerDotDetails) (string, error) {
+	req := elem.getInvokeRequest()
+
+	params := make(map[string]interface{})
+
+	setIfNotEmpty(params, "details", details)
+
+	req["params"] = map[string]interface{}{
+		"operation":       "getGstreamerDot",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	// // The dot graph
+
+	return response.Result["value"], response.Err()
 
 }
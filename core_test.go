@@ -0,0 +1,161 @@
+package kurento
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHubPorts(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, hub := range []interface {
+		IMediaObject
+		IHub
+	}{&Composite{}, &Mixer{}, &AlphaBlending{}, &Dispatcher{}, &DispatcherOneToMany{}} {
+		if err := pipeline.Create(hub, nil); err != nil {
+			t.Fatal(err)
+		}
+		a, err := hub.CreatePort(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		params := paramsOf(f.last())
+		if params["type"] != "HubPort" {
+			t.Errorf("created type is %v, want HubPort", params["type"])
+		}
+		if cp, _ := params["constructorParams"].(map[string]interface{}); cp["hub"] != hub.String() {
+			t.Errorf("hub of the port is %v, want %s", cp["hub"], hub)
+		}
+		b, err := hub.CreatePort(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ports := hub.Ports(); len(ports) != 2 || ports[0] != a || ports[1] != b {
+			t.Errorf("Ports() = %v, want [%s %s]", ports, a, b)
+		}
+
+		if err := a.Release(); err != nil {
+			t.Fatal(err)
+		}
+		if ports := hub.Ports(); len(ports) != 1 || ports[0] != b {
+			t.Errorf("Ports() = %v after a release, want [%s]", ports, b)
+		}
+
+		// the port then the hub are released
+		n := len(f.reqs)
+		if err := hub.Release(); err != nil {
+			t.Fatal(err)
+		}
+		released := []string{}
+		for _, req := range f.reqs[n:] {
+			if req["method"] == "release" {
+				released = append(released, paramsOf(req)["object"].(string))
+			}
+		}
+		if len(released) != 2 || released[0] != b.Id || released[1] != hub.String() {
+			t.Errorf("released %v, want [%s %s]", released, b.Id, hub)
+		}
+		if ports := hub.Ports(); len(ports) != 0 {
+			t.Errorf("Ports() = %v after the hub release", ports)
+		}
+	}
+}
+
+func TestHubCreatePortCanceled(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	mixer := &Mixer{}
+	if err := pipeline.Create(mixer, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server answers once the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["type"] == "HubPort" {
+			cancel()
+			time.Sleep(20 * time.Millisecond)
+			return map[string]interface{}{"value": "port1", "sessionId": "s1"}
+		}
+		return nil
+	}
+	if _, err := mixer.CreatePort(ctx); err != context.Canceled {
+		t.Fatalf("CreatePort() = %v, want %v", err, context.Canceled)
+	}
+	for i := 0; i < 100 && f.last()["method"] != "release"; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if req := f.last(); req["method"] != "release" || paramsOf(req)["object"] != "port1" {
+		t.Errorf("last request is %v, want the release of port1", req)
+	}
+	if ports := mixer.Ports(); len(ports) != 0 {
+		t.Errorf("Ports() = %v", ports)
+	}
+}
+
+func TestHubPortsAreTracked(t *testing.T) {
+	f := newFake(t)
+	f.reply = func(req map[string]interface{}) interface{} {
+		switch paramsOf(req)["operation"] {
+		case "getSourceConnections", "getSinkConnections":
+			return map[string]interface{}{"value": []interface{}{}}
+		}
+		return nil
+	}
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	webrtc := &WebRtcEndpoint{}
+	if err := pipeline.Create(webrtc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// ports created before and after TrackConnections
+	composite := &Composite{}
+	if err := pipeline.Create(composite, nil); err != nil {
+		t.Fatal(err)
+	}
+	before, err := composite.CreatePort(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pipeline.TrackConnections(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := composite.CreatePort(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, port := range []*HubPort{before, after} {
+		f.event(webrtc.Id, "ElementConnected", map[string]interface{}{"sink": port.Id, "mediaType": "VIDEO"})
+		f.event(port.Id, "ElementConnected", map[string]interface{}{"sink": webrtc.Id, "mediaType": "VIDEO"})
+	}
+	graph := pipeline.Graph()
+	for i := 0; i < 100 && len(graph.Connections()) < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, port := range []*HubPort{before, after} {
+		if sinks := graph.Sinks(port); len(sinks) != 1 || sinks[0].Sink.Id != webrtc.Id {
+			t.Errorf("sinks of %s are %v", port, sinks)
+		}
+	}
+	if sinks := graph.Sinks(webrtc); len(sinks) != 2 {
+		t.Errorf("sinks of the endpoint are %v", sinks)
+	}
+
+	// the hub itself is not subscribed
+	for _, req := range f.reqs {
+		if req["method"] == "subscribe" && paramsOf(req)["object"] == composite.Id {
+			t.Errorf("the hub is subscribed to %v", paramsOf(req)["type"])
+		}
+	}
+}
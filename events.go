@@ -0,0 +1,387 @@
+package kurento
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Event is implemented by all events raised by the media server. Handlers given
+// to Subscribe receive the typed event, e.g. *ElementConnected, or a
+// *MediaEvent if the event type is not known by the package.
+type Event interface {
+	getMediaEvent() *MediaEvent
+}
+
+// Base for all events raised by elements in the Kurento media server.
+type MediaEvent struct {
+	// Type of event that was raised
+	Type string
+
+	// Object that raised the event
+	Source string
+
+	// Time when the event was raised, as sent by the server
+	Timestamp string
+}
+
+// Implement Event interface
+func (e *MediaEvent) getMediaEvent() *MediaEvent {
+	return e
+}
+
+// Indicates that an element has been connected to another
+type ElementConnected struct {
+	MediaEvent
+
+	// The connection that was made, Source is the object that raised the event
+	Connection ElementConnectionData
+}
+
+// Build event from the "data" member of the notification
+func (e *ElementConnected) UnmarshalJSON(data []byte) error {
+	return decodeConnectionEvent(data, &e.MediaEvent, &e.Connection)
+}
+
+// Indicates that an element has been disconnected from another
+type ElementDisconnected struct {
+	MediaEvent
+
+	// The connection that was removed, Source is the object that raised the
+	// event
+	Connection ElementConnectionData
+}
+
+// Build event from the "data" member of the notification
+func (e *ElementDisconnected) UnmarshalJSON(data []byte) error {
+	return decodeConnectionEvent(data, &e.MediaEvent, &e.Connection)
+}
+
+// Fired when a session is established, i.e. media starts to flow through a
+// SessionEndpoint
+type MediaSessionStarted struct {
+	MediaEvent
+}
+
+// Fired when a session is terminated. This event is not fired if the
+// SessionEndpoint is released.
+type MediaSessionTerminated struct {
+	MediaEvent
+}
+
+// Fired when the incoming media of a sink pad starts or stops flowing. Only
+// available in KMS 6.
+type MediaFlowInStateChange struct {
+	MediaEvent
+
+	// Whether media is flowing
+	State MediaFlowState
+
+	// Name of the pad
+	PadName string
+
+	// Type of media of the pad
+	MediaType MediaType
+}
+
+// Fired when the outgoing media of a source pad starts or stops flowing. Only
+// available in KMS 6.
+type MediaFlowOutStateChange struct {
+	MediaEvent
+
+	// Whether media is flowing
+	State MediaFlowState
+
+	// Name of the pad
+	PadName string
+
+	// Type of media of the pad
+	MediaType MediaType
+}
+
+// Fired when the connection of a BaseRtpEndpoint changes. Only available in
+// KMS 6.
+type ConnectionStateChanged struct {
+	MediaEvent
+
+	OldState ConnectionState
+	NewState ConnectionState
+}
+
+// Fired when the media of a BaseRtpEndpoint changes, i.e. RTCP packets start
+// or stop being received. Only available in KMS 6.
+type MediaStateChanged struct {
+	MediaEvent
+
+	OldState MediaState
+	NewState MediaState
+}
+
+// Events known by the package, by name. See RegisterEvent
+var eventTypes = map[string]func() Event{
+	"ElementConnected":         func() Event { return &ElementConnected{} },
+	"ElementDisconnected":      func() Event { return &ElementDisconnected{} },
+	"MediaSessionStarted":      func() Event { return &MediaSessionStarted{} },
+	"MediaSessionTerminated":   func() Event { return &MediaSessionTerminated{} },
+	"Recording":                func() Event { return &Recording{} },
+	"Paused":                   func() Event { return &Paused{} },
+	"Stopped":                  func() Event { return &Stopped{} },
+	"CodeFound":                func() Event { return &CodeFound{} },
+	"CrowdDetectorFluidity":    func() Event { return &CrowdDetectorFluidity{} },
+	"CrowdDetectorOccupancy":   func() Event { return &CrowdDetectorOccupancy{} },
+	"CrowdDetectorDirection":   func() Event { return &CrowdDetectorDirection{} },
+	"PlateDetected":            func() Event { return &PlateDetected{} },
+	"WindowIn":                 func() Event { return &WindowIn{} },
+	"WindowOut":                func() Event { return &WindowOut{} },
+	"DataChannelOpened":        func() Event { return &DataChannelOpened{} },
+	"DataChannelClosed":        func() Event { return &DataChannelClosed{} },
+	"EndOfStream":              func() Event { return &EndOfStream{} },
+	"MediaFlowInStateChange":   func() Event { return &MediaFlowInStateChange{} },
+	"MediaFlowOutStateChange":  func() Event { return &MediaFlowOutStateChange{} },
+	"ConnectionStateChanged":   func() Event { return &ConnectionStateChanged{} },
+	"MediaStateChanged":        func() Event { return &MediaStateChanged{} },
+	"IceCandidateFound":        func() Event { return &IceCandidateFound{} },
+	"IceGatheringDone":         func() Event { return &IceGatheringDone{} },
+	"IceComponentStateChange":  func() Event { return &IceComponentStateChange{} },
+	"NewCandidatePairSelected": func() Event { return &NewCandidatePairSelected{} },
+}
+
+// Shared decoder for ElementConnected and ElementDisconnected
+func decodeConnectionEvent(data []byte, e *MediaEvent, conn *ElementConnectionData) error {
+	ev := struct {
+		MediaEvent
+		Sink                   string
+		MediaType              MediaType
+		SourceMediaDescription string
+		SinkMediaDescription   string
+	}{}
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	*e = ev.MediaEvent
+	conn.Source.Id = ev.Source
+	conn.Sink.Id = ev.Sink
+	conn.Type = ev.MediaType
+	conn.SourceDescription = ev.SourceMediaDescription
+	conn.SinkDescription = ev.SinkMediaDescription
+	return nil
+}
+
+// Build the typed event from a notification
+func decodeEvent(n notification) (Event, error) {
+	ev := newEvent(n.Params.Value.Type)
+	if ev == nil {
+		ev = &MediaEvent{}
+	}
+	if err := json.Unmarshal(n.Params.Value.Data, ev); err != nil {
+		return nil, err
+	}
+	e := ev.getMediaEvent()
+	if e.Type == "" {
+		e.Type = n.Params.Value.Type
+	}
+	if e.Source == "" {
+		e.Source = n.Params.Value.Object
+	}
+	return ev, nil
+}
+
+type eventHandler struct {
+	id string
+	fn func(Event)
+}
+
+// subscription is a server side subscription to one event type of one object.
+// Several handlers may share it.
+type subscription struct {
+	id       string
+	handlers []eventHandler
+
+	// closed when the server answered the subscribe request, err is set
+	// if it failed
+	ready chan struct{}
+	err   error
+}
+
+// Subscribe asks the server to send events of type eventType raised by this
+// object. The handler is called for each of them, in the order they were
+// received. Returned id can be given to Unsubscribe. If the event type is
+// being subscribed by another call, Subscribe waits for it and returns its
+// error.
+func (elem *MediaObject) Subscribe(eventType string, handler func(Event)) (string, error) {
+	c := elem.connection
+	key := elem.Id + "/" + eventType
+
+	c.mu.Lock()
+	c.handlerId++
+	h := eventHandler{fmt.Sprintf("%s/%d", key, c.handlerId), handler}
+	s := c.subscriptions[key]
+	if s != nil {
+		s.handlers = append(s.handlers, h)
+		c.mu.Unlock()
+		<-s.ready
+		if s.err != nil {
+			return "", s.err
+		}
+		return h.id, nil
+	}
+	s = &subscription{handlers: []eventHandler{h}, ready: make(chan struct{})}
+	c.subscriptions[key] = s
+	c.mu.Unlock()
+
+	req := elem.getSubscribeRequest()
+	req["params"] = map[string]interface{}{
+		"type":   c.serverEventName(eventType),
+		"object": elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-c.Request(req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(s.ready)
+	if response.Error != nil {
+		// the subscription may have been replaced if all handlers were
+		// removed in the meantime
+		if c.subscriptions[key] == s {
+			delete(c.subscriptions, key)
+		}
+		s.err = response.Err()
+		return "", s.err
+	}
+	s.id = response.Result["value"]
+	return h.id, nil
+}
+
+// Unsubscribe removes a handler registered with Subscribe. The server
+// subscription is released when the last handler of an event type is removed,
+// once the server answered the subscribe request.
+func (elem *MediaObject) Unsubscribe(id string) error {
+	c := elem.connection
+
+	c.mu.Lock()
+	var key string
+	var s *subscription
+	for k, sub := range c.subscriptions {
+		for i, h := range sub.handlers {
+			if h.id == id {
+				key, s = k, sub
+				sub.handlers = append(sub.handlers[:i], sub.handlers[i+1:]...)
+				break
+			}
+		}
+	}
+	if s == nil || len(s.handlers) > 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	delete(c.subscriptions, key)
+	c.mu.Unlock()
+
+	<-s.ready
+	if s.err != nil {
+		// nothing to release
+		return nil
+	}
+
+	req := elem.getUnsubscribeRequest()
+	req["params"] = map[string]interface{}{
+		"subscription": s.id,
+		"object":       elem.Id,
+	}
+
+	// Call server and wait response
+	response := <-c.Request(req)
+
+	// Returns error or nil
+	return response.Err()
+}
+
+// Remove all local subscriptions of an object, the server removes them when
+// the object is released
+func (c *Connection) dropSubscriptions(object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.subscriptions {
+		if strings.HasPrefix(key, object+"/") {
+			delete(c.subscriptions, key)
+		}
+	}
+}
+
+// Number of events kept while handlers are running, older events are dropped
+const maxQueuedEvents = 4096
+
+// Append an event to the queue and wake up the dispatcher
+func (c *Connection) queueEvent(n notification) {
+	c.mu.Lock()
+	if len(c.events) >= maxQueuedEvents {
+		if debug {
+			log.Println("Dropped event because the queue is full ", c.events[0].Params.Value.Type)
+		}
+		c.events = c.events[1:]
+	}
+	c.events = append(c.events, n)
+	c.mu.Unlock()
+
+	select {
+	case c.eventsReady <- struct{}{}:
+	default:
+	}
+}
+
+// Give received events to handlers and channel subscribers. Runs in its own
+// goroutine.
+func (c *Connection) dispatchEvents() {
+	for range c.eventsReady {
+		c.mu.Lock()
+		queue := c.events
+		c.events = nil
+		c.mu.Unlock()
+
+		for _, n := range queue {
+			c.dispatchEvent(n)
+		}
+	}
+}
+
+func (c *Connection) dispatchEvent(n notification) {
+	// events that have another name in the server API are given with the
+	// package name
+	name := packageEventName(n.Params.Value.Type)
+	renamed := name != n.Params.Value.Type
+	n.Params.Value.Type = name
+
+	ev, err := decodeEvent(n)
+	if err != nil {
+		if debug {
+			log.Println("Cannot decode event", n.Params.Value.Type, err)
+		}
+		return
+	}
+	if renamed {
+		ev.getMediaEvent().Type = name
+	}
+
+	c.mu.Lock()
+	var handlers []eventHandler
+	if s := c.subscriptions[n.Params.Value.Object+"/"+n.Params.Value.Type]; s != nil {
+		handlers = append(handlers, s.handlers...)
+	}
+	subscribers := append([]*eventSubscriber{}, c.subscribers...)
+	c.mu.Unlock()
+
+	if len(handlers) == 0 && len(subscribers) == 0 && debug {
+		log.Println("Dropped event because there is no handler ", n.Params.Value.Type)
+	}
+	for _, h := range handlers {
+		h.fn(ev)
+	}
+	for _, s := range subscribers {
+		if s.match(n.Params.Value.Object, ev) {
+			s.send(ev)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package kurento
+
+import (
+	"testing"
+	"time"
+)
+
+// Make the server answer subscribe requests once release is closed
+func delaySubscribe(f *fakeKMS, result interface{}) (started, release chan struct{}) {
+	started, release = make(chan struct{}, 1), make(chan struct{})
+	f.reply = func(req map[string]interface{}) interface{} {
+		if req["method"] != "subscribe" {
+			return nil
+		}
+		started <- struct{}{}
+		<-release
+		return result
+	}
+	return started, release
+}
+
+type subscribeResult struct {
+	id  string
+	err error
+}
+
+func TestSubscribeSharesInFlightSubscription(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	started, release := delaySubscribe(f, map[string]interface{}{"value": "sub1"})
+
+	received := make(chan string, 2)
+	results := make(chan subscribeResult, 2)
+	for _, name := range []string{"first", "second"} {
+		name := name
+		go func() {
+			id, err := pipeline.Subscribe("Error", func(Event) { received <- name })
+			results <- subscribeResult{id, err}
+		}()
+		if name == "first" {
+			<-started
+		}
+	}
+
+	select {
+	case r := <-results:
+		t.Fatalf("Subscribe returned %v before the server answered", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if r := <-results; r.err != nil || r.id == "" {
+			t.Errorf("Subscribe() = %q, %v", r.id, r.err)
+		}
+	}
+
+	f.event(pipeline.Id, "Error", map[string]interface{}{})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("a handler is not called")
+		}
+	}
+}
+
+func TestSubscribeSharesInFlightError(t *testing.T) {
+	f := newFake(t)
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	started, release := delaySubscribe(f, &Error{Code: 40101, Message: "Object not found"})
+
+	results := make(chan subscribeResult, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			id, err := pipeline.Subscribe("Error", func(Event) {})
+			results <- subscribeResult{id, err}
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for i := 0; i < 2; i++ {
+		if r := <-results; r.err == nil {
+			t.Error("Subscribe should return the error of the server")
+		}
+	}
+
+	// a new subscription is sent
+	f.mu.Lock()
+	f.reply = nil
+	f.mu.Unlock()
+	if _, err := pipeline.Subscribe("Error", func(Event) {}); err != nil {
+		t.Fatal(err)
+	}
+	if method := f.last()["method"]; method != "subscribe" {
+		t.Errorf("last method = %v, want subscribe", method)
+	}
+}
@@ -0,0 +1,291 @@
+package kurento
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// What to do when an event is received while the channel of a subscriber is
+// full.
+type OverflowPolicy string
+
+// Implement fmt.Stringer interface
+func (t OverflowPolicy) String() string {
+	return string(t)
+}
+
+const (
+	// Wait for the subscriber to read, no event is dropped. Up to 1024
+	// events are kept for the subscriber while other handlers and
+	// subscribers go on, then they wait too. This is the default.
+	OVERFLOWPOLICY_BLOCK OverflowPolicy = "BLOCK"
+	// Remove the oldest event of the channel to make room for the new one
+	OVERFLOWPOLICY_DROP_OLDEST OverflowPolicy = "DROP_OLDEST"
+	// Discard the new event
+	OVERFLOWPOLICY_DROP_NEWEST OverflowPolicy = "DROP_NEWEST"
+	// Close the channel, the subscriber will not receive any other event
+	OVERFLOWPOLICY_DISCONNECT OverflowPolicy = "DISCONNECT"
+)
+
+// Size of subscriber channels when EventFilter.Buffer is not set
+const defaultEventBuffer = 16
+
+// Number of events kept for a subscriber with OVERFLOWPOLICY_BLOCK before the
+// dispatcher waits for it
+const maxPendingEvents = 1024
+
+// EventFilter selects events sent to a channel returned by Events, and how
+// the channel is fed.
+type EventFilter struct {
+	// Event types to receive, e.g. "ElementConnected". All types if empty.
+	Types []string
+
+	// Objects ids to receive events from. All objects if empty.
+	Objects []string
+
+	// Size of the channel, 16 if not set
+	Buffer int
+
+	// What to do when the channel is full, OVERFLOWPOLICY_BLOCK if not set
+	Overflow OverflowPolicy
+}
+
+// A channel subscriber
+type eventSubscriber struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	filter  EventFilter
+	ch      chan Event
+	closed  bool
+	dropped uint64
+
+	// events waiting to be sent to ch with OVERFLOWPOLICY_BLOCK, in order
+	pending      []Event
+	pendingReady chan struct{}
+	pendingTaken chan struct{}
+}
+
+// EventStream is returned by Events.
+type EventStream struct {
+	// C receives the events. It is closed when the context given to Events is
+	// done, or when it overflows with OVERFLOWPOLICY_DISCONNECT.
+	C <-chan Event
+
+	s *eventSubscriber
+}
+
+// Dropped returns the number of events that were not sent to C because it
+// was full. It can be read after C is closed.
+func (e *EventStream) Dropped() uint64 {
+	return atomic.LoadUint64(&e.s.dropped)
+}
+
+// Events returns a stream that receives the events matching filter. Only
+// events the connection subscribed to are received, see MediaObject.Subscribe
+// and MediaObject.Events.
+func (c *Connection) Events(ctx context.Context, filter EventFilter) *EventStream {
+	return c.addSubscriber(ctx, filter, nil)
+}
+
+// Events subscribes to filter.Types events of the object and returns a
+// stream that receives them. If filter.Types is empty, no subscription is
+// made and the stream receives events of this object that are already
+// subscribed. The channel of the stream is also closed when a subscription
+// fails.
+func (elem *MediaObject) Events(ctx context.Context, filter EventFilter) *EventStream {
+	filter.Objects = []string{elem.Id}
+
+	ids := []string{}
+	unsubscribe := func() {
+		for _, id := range ids {
+			elem.Unsubscribe(id)
+		}
+	}
+	for _, t := range filter.Types {
+		// events are given to the channel by the dispatcher, the handler only
+		// keeps the subscription
+		id, err := elem.Subscribe(t, func(Event) {})
+		if err != nil {
+			if debug {
+				log.Println("Cannot subscribe to", t, err)
+			}
+			unsubscribe()
+			ch := make(chan Event)
+			close(ch)
+			return &EventStream{C: ch, s: &eventSubscriber{closed: true}}
+		}
+		ids = append(ids, id)
+	}
+
+	return elem.connection.addSubscriber(ctx, filter, unsubscribe)
+}
+
+// Register a channel subscriber, onClose is called after it is removed
+func (c *Connection) addSubscriber(ctx context.Context, filter EventFilter, onClose func()) *EventStream {
+	if filter.Buffer <= 0 {
+		filter.Buffer = defaultEventBuffer
+	}
+	s := &eventSubscriber{
+		filter:       filter,
+		ch:           make(chan Event, filter.Buffer),
+		pendingReady: make(chan struct{}, 1),
+		pendingTaken: make(chan struct{}, 1),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.subscribers = append(c.subscribers, s)
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.deliverPending()
+		close(done)
+	}()
+	go func() {
+		<-s.ctx.Done()
+		<-done
+		c.removeSubscriber(s)
+		if onClose != nil {
+			onClose()
+		}
+	}()
+
+	return &EventStream{C: s.ch, s: s}
+}
+
+// Remove subscriber and close its channel
+func (c *Connection) removeSubscriber(s *eventSubscriber) {
+	c.mu.Lock()
+	for i, sub := range c.subscribers {
+		if sub == s {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// Check if the event is selected by the subscriber filter
+func (s *eventSubscriber) match(object string, ev Event) bool {
+	return matchAny(s.filter.Objects, object) &&
+		matchAny(s.filter.Types, ev.getMediaEvent().Type)
+}
+
+// Check if v is in list, or if list is empty
+func matchAny(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Give event to the subscriber channel, following the overflow policy
+func (s *eventSubscriber) send(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	if s.filter.Overflow == "" || s.filter.Overflow == OVERFLOWPOLICY_BLOCK {
+		s.block(ev)
+		return
+	}
+
+	select {
+	case s.ch <- ev:
+		return
+	default:
+	}
+
+	switch s.filter.Overflow {
+	case OVERFLOWPOLICY_DROP_NEWEST:
+		atomic.AddUint64(&s.dropped, 1)
+	case OVERFLOWPOLICY_DROP_OLDEST:
+		for {
+			select {
+			case <-s.ch:
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+		}
+	case OVERFLOWPOLICY_DISCONNECT:
+		atomic.AddUint64(&s.dropped, 1)
+		s.closed = true
+		s.cancel()
+	}
+}
+
+// Queue the event for deliverPending, that is the only one to write to the
+// channel so that events keep their order. Waits while the queue is full.
+// s.mu must be locked.
+func (s *eventSubscriber) block(ev Event) {
+	for len(s.pending) >= maxPendingEvents {
+		s.mu.Unlock()
+		select {
+		case <-s.pendingTaken:
+		case <-s.ctx.Done():
+		}
+		s.mu.Lock()
+		if s.closed || s.ctx.Err() != nil {
+			atomic.AddUint64(&s.dropped, 1)
+			return
+		}
+	}
+	s.pending = append(s.pending, ev)
+	select {
+	case s.pendingReady <- struct{}{}:
+	default:
+	}
+}
+
+// Give pending events to the channel, waiting for the subscriber to read
+// them. Runs in its own goroutine until the subscriber is done.
+func (s *eventSubscriber) deliverPending() {
+	for {
+		select {
+		case <-s.pendingReady:
+		case <-s.ctx.Done():
+			return
+		}
+		for {
+			s.mu.Lock()
+			if len(s.pending) == 0 {
+				s.mu.Unlock()
+				break
+			}
+			ev := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+			select {
+			case s.pendingTaken <- struct{}{}:
+			default:
+			}
+
+			select {
+			case s.ch <- ev:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}
+}
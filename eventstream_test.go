@@ -0,0 +1,112 @@
+package kurento
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEventStreams(t *testing.T) {
+	f := newFake(t)
+	c := f.conn()
+	p := &MediaPipeline{}
+	c.Create(p, nil)
+	r := &RecorderEndpoint{}
+	p.Create(r, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oldest := r.Events(ctx, EventFilter{Types: []string{"Recording", "Stopped"}, Buffer: 2, Overflow: OVERFLOWPOLICY_DROP_OLDEST})
+	newest := c.Events(ctx, EventFilter{Buffer: 1, Overflow: OVERFLOWPOLICY_DROP_NEWEST})
+	disconnect := c.Events(ctx, EventFilter{Types: []string{"Stopped"}, Buffer: 1, Overflow: OVERFLOWPOLICY_DISCONNECT})
+	blocked := c.Events(ctx, EventFilter{Buffer: 1})
+
+	// a handler must be called although "blocked" is not read
+	stopped := make(chan Event, 2)
+	if _, err := r.Subscribe("Stopped", func(ev Event) { stopped <- ev }); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		f.event(r.Id, "Recording", map[string]interface{}{"timestamp": strconv.Itoa(i)})
+	}
+	f.event(r.Id, "Stopped", map[string]interface{}{})
+	f.event(r.Id, "Stopped", map[string]interface{}{})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("handler is blocked by a subscriber")
+		}
+	}
+
+	if d := oldest.Dropped(); d != 5 {
+		t.Errorf("DROP_OLDEST dropped %d events, want 5", d)
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := (<-oldest.C).(*Stopped); !ok {
+			t.Error("DROP_OLDEST should keep the last events")
+		}
+	}
+
+	if d := newest.Dropped(); d != 6 {
+		t.Errorf("DROP_NEWEST dropped %d events, want 6", d)
+	}
+	if e, ok := (<-newest.C).(*Recording); !ok || e.Timestamp != "0" {
+		t.Error("DROP_NEWEST should keep the first event")
+	}
+
+	<-disconnect.C
+	if _, ok := <-disconnect.C; ok {
+		t.Error("DISCONNECT channel should be closed on overflow")
+	}
+	if d := disconnect.Dropped(); d != 1 {
+		t.Errorf("DISCONNECT dropped %d events, want 1", d)
+	}
+
+	// events wait for the blocked subscriber
+	for i := 0; i < 7; i++ {
+		select {
+		case <-blocked.C:
+		case <-time.After(time.Second):
+			t.Fatalf("BLOCK subscriber received %d events, want 7", i)
+		}
+	}
+	if d := blocked.Dropped(); d != 0 {
+		t.Errorf("BLOCK dropped %d events", d)
+	}
+}
+
+func TestEventStreamBlockKeepsOrder(t *testing.T) {
+	f := newFake(t)
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// more events than the channel and the pending queue can hold, they are
+	// read once all are sent
+	n := defaultEventBuffer + maxPendingEvents + 100
+	events := c.Events(ctx, EventFilter{Types: []string{"Recording"}})
+	for i := 0; i < n; i++ {
+		f.event(pipeline.Id, "Recording", map[string]interface{}{"timestamp": strconv.Itoa(i)})
+	}
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-events.C:
+			if ts := ev.(*Recording).Timestamp; ts != strconv.Itoa(i) {
+				t.Fatalf("event %d has timestamp %s", i, ts)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d events, want %d", i, n)
+		}
+	}
+	if d := events.Dropped(); d != 0 {
+		t.Errorf("BLOCK dropped %d events", d)
+	}
+}
@@ -0,0 +1,179 @@
+package kurento
+
+import (
+	"log"
+	"sync"
+)
+
+// ConnectionGraph is a local view of connections between elements of a
+// pipeline. It is updated from ElementConnected and ElementDisconnected events,
+// so it also reflects connections made by other clients of the media server.
+type ConnectionGraph struct {
+	mu          sync.RWMutex
+	connections []ElementConnectionData
+}
+
+// TrackConnections keeps a ConnectionGraph of the pipeline elements up to date.
+// Elements already created from the pipeline, and the ones that will be
+// created later, are subscribed to ElementConnected and ElementDisconnected
+// events. Ports of hubs, such as `Composite` and `Mixer`, are tracked too.
+// The graph starts with the current connections of the elements already
+// created.
+func (elem *MediaPipeline) TrackConnections() error {
+	if elem.graph != nil {
+		return nil
+	}
+	elem.graph = &ConnectionGraph{}
+	elements := []IMediaObject{}
+	for _, child := range elem.Childs {
+		elements = append(elements, child)
+		if h, ok := child.(graphHolder); ok {
+			h.setGraph(elem.graph)
+		}
+		if h, ok := child.(IHub); ok {
+			for _, port := range h.Ports() {
+				elements = append(elements, port)
+			}
+		}
+	}
+	for _, child := range elements {
+		if err := elem.graph.track(child); err != nil {
+			return err
+		}
+	}
+	// read connections once subscribed, so none is missed
+	for _, child := range elements {
+		if err := elem.graph.seed(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Objects that keep the graph of their pipeline to track the objects they
+// create, i.e. hubs and their ports
+type graphHolder interface {
+	setGraph(*ConnectionGraph)
+}
+
+// Implement graphHolder
+func (elem *Hub) setGraph(g *ConnectionGraph) {
+	elem.graph = g
+}
+
+// Graph returns the connection graph of the pipeline, or nil if
+// TrackConnections was not called.
+func (elem *MediaPipeline) Graph() *ConnectionGraph {
+	return elem.graph
+}
+
+// Connections returns all known connections.
+func (g *ConnectionGraph) Connections() []ElementConnectionData {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]ElementConnectionData{}, g.connections...)
+}
+
+// Sinks returns connections where the given element sends media.
+func (g *ConnectionGraph) Sinks(source IMediaObject) []ElementConnectionData {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	ret := []ElementConnectionData{}
+	for _, c := range g.connections {
+		if c.Source.Id == source.String() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Sources returns connections where the given element receives media.
+func (g *ConnectionGraph) Sources(sink IMediaObject) []ElementConnectionData {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	ret := []ElementConnectionData{}
+	for _, c := range g.connections {
+		if c.Sink.Id == sink.String() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Subscribe m to connection events that update the graph. Only media
+// elements raise them.
+func (g *ConnectionGraph) track(m IMediaObject) error {
+	if _, ok := m.(IMediaElement); !ok {
+		return nil
+	}
+	if _, err := m.Subscribe("ElementConnected", g.handleEvent); err != nil {
+		if debug {
+			log.Println("Cannot track connections of", m, err)
+		}
+		return err
+	}
+	_, err := m.Subscribe("ElementDisconnected", g.handleEvent)
+	return err
+}
+
+// Add the current connections of an element to the graph
+func (g *ConnectionGraph) seed(m IMediaObject) error {
+	elem, ok := m.(IMediaElement)
+	if !ok {
+		return nil
+	}
+	sources, err := elem.GetSourceConnections("", "")
+	if err != nil {
+		return err
+	}
+	sinks, err := elem.GetSinkConnections("", "")
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, c := range append(sources, sinks...) {
+		g.add(c)
+	}
+	return nil
+}
+
+// Add a connection that is not known yet, g.mu must be locked
+func (g *ConnectionGraph) add(conn ElementConnectionData) {
+	for _, c := range g.connections {
+		if sameConnection(c, conn) {
+			return
+		}
+	}
+	g.connections = append(g.connections, conn)
+}
+
+// Update the graph from an event
+func (g *ConnectionGraph) handleEvent(ev Event) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *ElementConnected:
+		g.add(e.Connection)
+	case *ElementDisconnected:
+		kept := g.connections[:0]
+		for _, c := range g.connections {
+			if !sameConnection(c, e.Connection) {
+				kept = append(kept, c)
+			}
+		}
+		g.connections = kept
+	}
+}
+
+// Check if a and b are the same connection, empty type or descriptions in b
+// match any value
+func sameConnection(a, b ElementConnectionData) bool {
+	return a.Source.Id == b.Source.Id &&
+		a.Sink.Id == b.Sink.Id &&
+		(b.Type == "" || a.Type == b.Type) &&
+		(b.SourceDescription == "" || a.SourceDescription == b.SourceDescription) &&
+		(b.SinkDescription == "" || a.SinkDescription == b.SinkDescription)
+}
@@ -0,0 +1,75 @@
+package kurento
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackConnectionsSeedsGraph(t *testing.T) {
+	f := newFake(t)
+	var a, b WebRtcEndpoint
+	f.reply = func(req map[string]interface{}) interface{} {
+		switch paramsOf(req)["operation"] {
+		case "getSinkConnections":
+			if paramsOf(req)["object"] == a.Id {
+				return map[string]interface{}{"value": []interface{}{
+					map[string]interface{}{"source": a.Id, "sink": b.Id, "type": "VIDEO"},
+				}}
+			}
+			return map[string]interface{}{"value": []interface{}{}}
+		case "getSourceConnections":
+			if paramsOf(req)["object"] == b.Id {
+				return map[string]interface{}{"value": []interface{}{
+					map[string]interface{}{"source": a.Id, "sink": b.Id, "type": "VIDEO"},
+				}}
+			}
+			return map[string]interface{}{"value": []interface{}{}}
+		}
+		return nil
+	}
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	c.Create(pipeline, nil)
+	pipeline.Create(&a, nil)
+	pipeline.Create(&b, nil)
+
+	if err := pipeline.TrackConnections(); err != nil {
+		t.Fatal(err)
+	}
+	conns := pipeline.Graph().Connections()
+	if len(conns) != 1 || conns[0].Source.Id != a.Id || conns[0].Sink.Id != b.Id || conns[0].Type != MEDIATYPE_VIDEO {
+		t.Fatalf("graph is not seeded: %v", conns)
+	}
+
+	f.event(a.Id, "ElementDisconnected", map[string]interface{}{"sink": b.Id, "mediaType": "VIDEO"})
+	for i := 0; i < 100 && len(pipeline.Graph().Connections()) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if conns := pipeline.Graph().Connections(); len(conns) != 0 {
+		t.Fatalf("connection is not removed: %v", conns)
+	}
+}
+
+func TestCreateReturnsTrackingError(t *testing.T) {
+	f := newFake(t)
+	f.reply = func(req map[string]interface{}) interface{} {
+		if req["method"] == "subscribe" && paramsOf(req)["object"] == "obj2" {
+			return &Error{Code: 40101, Message: "subscription failed"}
+		}
+		return nil
+	}
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	c.Create(pipeline, nil)
+	if err := pipeline.TrackConnections(); err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint := &WebRtcEndpoint{}
+	if err := pipeline.Create(endpoint, nil); err == nil {
+		t.Fatal("tracking error is not returned")
+	}
+	if endpoint.Id != "obj2" {
+		t.Fatalf("endpoint id is %q", endpoint.Id)
+	}
+}
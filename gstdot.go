@@ -0,0 +1,216 @@
+package kurento
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// GstreamerDotGetter is implemented by MediaPipeline and all media elements.
+type GstreamerDotGetter interface {
+	GetGstreamerDot(details GstreamerDotDetails) (string, error)
+}
+
+// GstreamerDotElement is a GStreamer element found in a DOT graph
+type GstreamerDotElement struct {
+	// GStreamer type, e.g. "GstQueue"
+	Type string
+
+	// Element name, e.g. "queue0"
+	Name string
+}
+
+// GstreamerDotLink is a link between pads of two GStreamer elements
+type GstreamerDotLink struct {
+	Source    string
+	SourcePad string
+	Sink      string
+	SinkPad   string
+
+	// Caps negotiated on the link, e.g. "video/x-raw, format=I420". Only
+	// the media type is given unless the graph has been retrieved with
+	// SHOW_CAPS_DETAILS or more. Empty if caps are not negotiated yet.
+	Caps string
+}
+
+// GstreamerDotSummary summarizes a DOT graph returned by GetGstreamerDot
+type GstreamerDotSummary struct {
+	Elements []GstreamerDotElement
+	Links    []GstreamerDotLink
+}
+
+// Implement fmt.Stringer interface
+func (s *GstreamerDotSummary) String() string {
+	lines := []string{fmt.Sprintf("%d elements, %d links", len(s.Elements), len(s.Links))}
+	for _, l := range s.Links {
+		caps := l.Caps
+		if caps == "" {
+			caps = "not negotiated"
+		}
+		lines = append(lines, fmt.Sprintf("%s.%s -> %s.%s: %s", l.Source, l.SourcePad, l.Sink, l.SinkPad, caps))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	dotCluster = regexp.MustCompile(`^subgraph\s+(\S+)\s*\{$`)
+	dotEdge    = regexp.MustCompile(`^"?(\w+)"?\s*->\s*"?(\w+)"?\s*(?:\[(.*)\])?;?$`)
+	dotNode    = regexp.MustCompile(`^"?(\w+)"?\s*\[(.*)\];?$`)
+	dotLabel   = regexp.MustCompile(`^label="((?:[^"\\]|\\.)*)";?$`)
+)
+
+// WriteGstreamerDot retrieves the GStreamer graph of obj, writes it to
+// filename, e.g. to render it with graphviz, and returns its summary.
+func WriteGstreamerDot(obj GstreamerDotGetter, details GstreamerDotDetails, filename string) (*GstreamerDotSummary, error) {
+	dot, err := obj.GetGstreamerDot(details)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filename, []byte(dot), 0644); err != nil {
+		return nil, err
+	}
+	return ParseGstreamerDot(dot)
+}
+
+// ParseGstreamerDot reads a DOT graph generated by GStreamer, and returns its
+// elements and the links between them. Other DOT graphs are not supported.
+func ParseGstreamerDot(dot string) (*GstreamerDotSummary, error) {
+	if !strings.HasPrefix(strings.TrimSpace(dot), "digraph") {
+		return nil, fmt.Errorf("gstreamer dot: not a digraph")
+	}
+
+	// clusters are elements when they have a label, pads otherwise
+	type cluster struct {
+		element *GstreamerDotElement
+	}
+	summary := &GstreamerDotSummary{}
+	stack := []*cluster{}
+	depth := 0
+	// element and pad names by node id
+	pads := map[string][2]string{}
+	edges := [][3]string{}
+
+	for _, line := range strings.Split(dot, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "digraph"):
+			depth++
+		case dotCluster.MatchString(line):
+			stack = append(stack, &cluster{})
+			depth++
+		case line == "}":
+			if depth == 0 {
+				return nil, fmt.Errorf("gstreamer dot: unbalanced braces")
+			}
+			depth--
+			if len(stack) > 0 && depth == len(stack) {
+				stack = stack[:len(stack)-1]
+			}
+		case dotLabel.MatchString(line):
+			if len(stack) == 0 {
+				// label of the pipeline itself
+				continue
+			}
+			label := splitDotLabel(dotLabel.FindStringSubmatch(line)[1], `\n`)
+			if len(label) < 2 || label[0] == "" {
+				continue
+			}
+			c := stack[len(stack)-1]
+			c.element = &GstreamerDotElement{
+				Type: strings.Trim(label[0], "<>"),
+				Name: label[1],
+			}
+			summary.Elements = append(summary.Elements, *c.element)
+		case dotEdge.MatchString(line):
+			m := dotEdge.FindStringSubmatch(line)
+			attrs := m[3]
+			// invisible edges order pads, dashed ones link ghost pads to
+			// their target in the same bin
+			if strings.Contains(attrs, "invis") || strings.Contains(attrs, "dashed") {
+				continue
+			}
+			edges = append(edges, [3]string{m[1], m[2], dotEdgeCaps(attrs)})
+		case dotNode.MatchString(line):
+			m := dotNode.FindStringSubmatch(line)
+			if m[1] == "node" || m[1] == "edge" || m[1] == "graph" {
+				continue
+			}
+			pad := ""
+			if label, ok := dotAttr(m[2], "label"); ok {
+				pad = splitDotLabel(label, `\n`)[0]
+			}
+			element := ""
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].element != nil {
+					element = stack[i].element.Name
+					break
+				}
+			}
+			pads[m[1]] = [2]string{element, pad}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("gstreamer dot: unbalanced braces")
+	}
+
+	for _, e := range edges {
+		src, sink := pads[e[0]], pads[e[1]]
+		summary.Links = append(summary.Links, GstreamerDotLink{
+			Source:    src[0],
+			SourcePad: src[1],
+			Sink:      sink[0],
+			SinkPad:   sink[1],
+			Caps:      e[2],
+		})
+	}
+	return summary, nil
+}
+
+// Return the value of a quoted attribute in a DOT attribute list
+func dotAttr(attrs string, name string) (string, bool) {
+	re := regexp.MustCompile(`(?:^|[\s,])` + name + `="((?:[^"\\]|\\.)*)"`)
+	m := re.FindStringSubmatch(attrs)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Split a DOT label on escaped line breaks, lines are trimmed
+func splitDotLabel(label string, sep string) []string {
+	lines := []string{}
+	for _, l := range strings.Split(label, sep) {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	return lines
+}
+
+// Return the caps of an edge in GStreamer caps format. Caps are given in the
+// label, or in taillabel and headlabel when they differ on each side.
+func dotEdgeCaps(attrs string) string {
+	label, _ := dotAttr(attrs, "label")
+	if strings.TrimSpace(label) == "" {
+		label, _ = dotAttr(attrs, "taillabel")
+	}
+
+	// lines are "media/type" followed by "field: value" lines
+	structures := []string{}
+	for _, l := range splitDotLabel(label, `\l`) {
+		if l == "" {
+			continue
+		}
+		kv := strings.SplitN(l, ": ", 2)
+		if len(kv) == 2 && len(structures) > 0 {
+			structures[len(structures)-1] += ", " + kv[0] + "=" + kv[1]
+			continue
+		}
+		structures = append(structures, l)
+	}
+	return strings.Join(structures, "; ")
+}
@@ -0,0 +1,120 @@
+package kurento
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Read testdata/gstreamer.dot, that is dumped by GStreamer 1.22 with
+// GST_DEBUG_GRAPH_SHOW_ALL from the pipeline:
+//
+//	fakesrc sizetype=fixed sizemax=460800
+//	! capsfilter caps="video/x-raw,format=I420,width=640,height=480,framerate=30/1"
+//	! tee name=t ! queue ! fakesink t. ! bin.( queue ! identity ) ! fakesink
+func readTestDot(t *testing.T) string {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "gstreamer.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestParseGstreamerDot(t *testing.T) {
+	summary, err := ParseGstreamerDot(readTestDot(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements := []GstreamerDotElement{
+		{"GstFakeSink", "fakesink1"},
+		{"GstBin", "bin0"},
+		{"GstIdentity", "identity0"},
+		{"GstQueue", "queue1"},
+		{"GstFakeSink", "fakesink0"},
+		{"GstQueue", "queue0"},
+		{"GstTee", "t"},
+		{"GstCapsFilter", "capsfilter0"},
+		{"GstFakeSrc", "fakesrc0"},
+	}
+	if !reflect.DeepEqual(summary.Elements, elements) {
+		t.Errorf("Elements = %v, want %v", summary.Elements, elements)
+	}
+
+	caps := "video/x-raw, format=I420, width=640, height=480, framerate=30/1"
+	links := []GstreamerDotLink{
+		{"identity0", "src", "bin0", "proxypad1", caps},
+		{"bin0", "proxypad0", "queue1", "sink", caps},
+		{"queue1", "src", "identity0", "sink", caps},
+		{"bin0", "ghost1", "fakesink1", "sink", caps},
+		{"queue0", "src", "fakesink0", "sink", caps},
+		{"t", "src_0", "queue0", "sink", caps},
+		{"t", "src_1", "bin0", "ghost0", caps},
+		{"capsfilter0", "src", "t", "sink", caps},
+		{"fakesrc0", "src", "capsfilter0", "sink", "ANY"},
+	}
+	if !reflect.DeepEqual(summary.Links, links) {
+		t.Errorf("Links = %v, want %v", summary.Links, links)
+	}
+}
+
+func TestParseGstreamerDotErrors(t *testing.T) {
+	for _, dot := range []string{
+		"",
+		"graph pipeline {\n}\n",
+		"digraph pipeline {\n",
+		"digraph pipeline {\n}\n}\n",
+		"digraph pipeline {\n  subgraph cluster_queue0 {\n}\n",
+	} {
+		if _, err := ParseGstreamerDot(dot); err == nil {
+			t.Errorf("ParseGstreamerDot(%q) should fail", dot)
+		}
+	}
+}
+
+func TestDotEdgeCaps(t *testing.T) {
+	tests := []struct {
+		attrs, want string
+	}{
+		{`label="ANY"`, "ANY"},
+		{`label=""`, ""},
+		{`label="audio/x-raw\l              format: S16LE\l                rate: 48000\l"`, "audio/x-raw, format=S16LE, rate=48000"},
+		{`label="", taillabel="video/x-raw\l               width: 320\lvideo/x-raw(memory:GLMemory)\l               width: 320\l", headlabel="ANY"`, "video/x-raw, width=320; video/x-raw(memory:GLMemory), width=320"},
+	}
+	for _, tt := range tests {
+		if got := dotEdgeCaps(tt.attrs); got != tt.want {
+			t.Errorf("dotEdgeCaps(%q) = %q, want %q", tt.attrs, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGstreamerDot(t *testing.T) {
+	dot := readTestDot(t)
+	f := newFake(t)
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] == "getGstreamerDot" {
+			return map[string]interface{}{"value": dot}
+		}
+		return nil
+	}
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "pipeline.dot")
+	summary, err := WriteGstreamerDot(pipeline, GSTREAMERDOTDETAILS_SHOW_ALL, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Elements) != 9 || len(summary.Links) != 9 {
+		t.Errorf("summary has %d elements and %d links, want 9 and 9", len(summary.Elements), len(summary.Links))
+	}
+	if details := operationParamsOf(f.last())["details"]; details != "SHOW_ALL" {
+		t.Errorf("details = %v, want SHOW_ALL", details)
+	}
+	if b, _ := ioutil.ReadFile(filename); string(b) != dot {
+		t.Error("the written file differs from the graph")
+	}
+}
@@ -0,0 +1,104 @@
+package kurento
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeKMS is a websocket server that answers JSON-RPC requests like a media
+// server, and records them
+type fakeKMS struct {
+	srv *httptest.Server
+
+	mu   sync.Mutex
+	ws   *websocket.Conn
+	reqs []map[string]interface{}
+	n    int
+
+	// reply returns the result of a request, nil for the default result: a
+	// new object id as "value". An *Error is sent as the request error.
+	reply func(req map[string]interface{}) interface{}
+}
+
+// The server is not closed, the reading goroutine of the connection would
+// spin on a closed websocket
+func newFake(t *testing.T) *fakeKMS {
+	f := &fakeKMS{}
+	f.srv = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		f.mu.Lock()
+		f.ws = ws
+		f.mu.Unlock()
+		for {
+			var req map[string]interface{}
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.reqs = append(f.reqs, req)
+			f.n++
+			var res interface{} = map[string]interface{}{
+				"value":     "obj" + strconv.Itoa(f.n),
+				"sessionId": "s1",
+			}
+			if f.reply != nil {
+				if r := f.reply(req); r != nil {
+					res = r
+				}
+			}
+			f.mu.Unlock()
+			if e, ok := res.(*Error); ok {
+				websocket.JSON.Send(ws, map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "error": e})
+				continue
+			}
+			websocket.JSON.Send(ws, map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": res})
+		}
+	}))
+	return f
+}
+
+// Open a connection to the server
+func (f *fakeKMS) conn() *Connection {
+	return NewConnection(strings.Replace(f.srv.URL, "http", "ws", 1))
+}
+
+// Send an event raised by obj
+func (f *fakeKMS) event(obj, typ string, data map[string]interface{}) {
+	data["type"] = typ
+	if data["source"] == nil {
+		data["source"] = obj
+	}
+	f.mu.Lock()
+	ws := f.ws
+	f.mu.Unlock()
+	websocket.JSON.Send(ws, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "onEvent",
+		"params": map[string]interface{}{
+			"value": map[string]interface{}{"data": data, "object": obj, "type": typ},
+		},
+	})
+}
+
+// Return the last request received
+func (f *fakeKMS) last() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reqs[len(f.reqs)-1]
+}
+
+// Return "params" of a request
+func paramsOf(req map[string]interface{}) map[string]interface{} {
+	p, _ := req["params"].(map[string]interface{})
+	return p
+}
+
+// Return "operationParams" of an invoke request
+func operationParamsOf(req map[string]interface{}) map[string]interface{} {
+	p, _ := paramsOf(req)["operationParams"].(map[string]interface{})
+	return p
+}
@@ -0,0 +1,91 @@
+package kurento
+
+import (
+	"fmt"
+	"log"
+)
+
+// Id of the ServerManager object, created by the media server
+const serverManagerId = "manager_ServerManager"
+
+// Elements of optional KMS modules
+type moduleElement interface {
+	kmsModule() string
+}
+
+// ServerManager returns the object that manages the media server.
+func (c *Connection) ServerManager() *ServerManager {
+	m := &ServerManager{}
+	m.setConnection(c)
+	m.setId(serverManagerId)
+	return m
+}
+
+// ServerInfo returns the media server information, that is fetched once by
+// connection. Create uses it to check that the module of a filter, e.g. the
+// "chroma" module of ChromaFilter, is installed. If ServerInfo fails, the
+// check is skipped and the request is sent to the server.
+func (c *Connection) ServerInfo() (*ServerInfo, error) {
+	c.mu.Lock()
+	info := c.serverInfo
+	c.mu.Unlock()
+	if info != nil {
+		return info, nil
+	}
+
+	info, err := c.ServerManager().GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.serverInfo = info
+	c.mu.Unlock()
+	return info, nil
+}
+
+// HasModule checks if the media server has the given module, e.g. "chroma".
+func (info *ServerInfo) HasModule(name string) bool {
+	for _, m := range info.Modules {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFactory checks if a module of the media server can create the given
+// type.
+func (info *ServerInfo) HasFactory(name string) bool {
+	for _, m := range info.Modules {
+		for _, f := range m.Factories {
+			if f == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check that the module of m is available before creating it. If server
+// information is not available, nil is returned and the server will answer.
+func (c *Connection) checkModule(m IMediaObject) error {
+	me, ok := m.(moduleElement)
+	if !ok {
+		return nil
+	}
+
+	info, err := c.ServerInfo()
+	if err != nil {
+		if debug {
+			log.Println("Cannot check modules of the server", err)
+		}
+		return nil
+	}
+
+	name := getMediaElementType(m)
+	if !info.HasModule(me.kmsModule()) && !info.HasFactory(name) {
+		return fmt.Errorf("kurento: %s needs the %q module that is not installed on the media server", name, me.kmsModule())
+	}
+	return nil
+}
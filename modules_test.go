@@ -0,0 +1,76 @@
+package kurento
+
+import (
+	"strings"
+	"testing"
+)
+
+// Answer getInfo with the given modules, name and factories
+func fakeModules(f *fakeKMS, modules map[string][]string) {
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] != "getInfo" {
+			return nil
+		}
+		list := []interface{}{}
+		for name, factories := range modules {
+			list = append(list, map[string]interface{}{"name": name, "version": "6.6.0", "factories": factories})
+		}
+		return map[string]interface{}{"value": map[string]interface{}{"version": "6.6.0", "type": "KMS", "modules": list}}
+	}
+}
+
+func TestCreateChecksModule(t *testing.T) {
+	f := newFake(t)
+	fakeModules(f, map[string][]string{
+		"core":   {"MediaPipeline"},
+		"chroma": {"ChromaFilter"},
+		// factory in a module with another name
+		"kurento-platedetector": {"PlateDetectorFilter"},
+	})
+	c := f.conn()
+	pipeline := &MediaPipeline{}
+	if err := c.Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	n := len(f.reqs)
+	err := pipeline.Create(&CrowdDetectorFilter{}, map[string]interface{}{
+		"rois": []RegionOfInterest{{Id: "roi0"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `"crowddetector" module`) {
+		t.Errorf("Create() = %v, want an error about the crowddetector module", err)
+	}
+	// getInfo only
+	if len(f.reqs) != n+1 {
+		t.Errorf("%d requests are sent, want 1", len(f.reqs)-n)
+	}
+
+	if err := pipeline.Create(&ChromaFilter{}, map[string]interface{}{"window": WindowParam{0, 0, 10, 10}}); err != nil {
+		t.Error(err)
+	}
+	if err := pipeline.Create(&PlateDetectorFilter{}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateWithoutServerInfo(t *testing.T) {
+	f := newFake(t)
+	f.reply = func(req map[string]interface{}) interface{} {
+		if paramsOf(req)["operation"] == "getInfo" {
+			return &Error{Code: -32000, Message: "Unexpected error"}
+		}
+		return nil
+	}
+	pipeline := &MediaPipeline{}
+	if err := f.conn().Create(pipeline, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server answers
+	if err := pipeline.Create(&PlateDetectorFilter{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if typ := paramsOf(f.last())["type"]; typ != "PlateDetectorFilter" {
+		t.Errorf("last created type is %v, want PlateDetectorFilter", typ)
+	}
+}
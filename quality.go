@@ -0,0 +1,354 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StatsGetter is an element that can be polled by a QualityMonitor, all
+// media elements implement it.
+type StatsGetter interface {
+	GetStats(mediaType MediaType) (map[string]Stats, error)
+	String() string
+}
+
+// QualitySample is the media quality of an element between two polls of a
+// QualityMonitor.
+type QualitySample struct {
+	// Element id
+	Element string
+
+	// Time of the poll
+	Time time.Time
+
+	// Time since the previous poll
+	Duration time.Duration
+
+	// Received and sent bitrates, in bits per second
+	InboundBitrate  float64
+	OutboundBitrate float64
+
+	// Percentage of packets lost by received streams
+	PacketLossPercent float64
+
+	// Highest jitter of received streams, in seconds
+	Jitter float64
+
+	// Change of jitter since the previous poll, in seconds per second.
+	// Positive when the jitter grows.
+	JitterTrend float64
+
+	// Highest round trip time, in seconds
+	RoundTripTime float64
+}
+
+// Metrics checked against QualityThresholds
+const (
+	QUALITY_PACKET_LOSS     = "packetLoss"
+	QUALITY_JITTER          = "jitter"
+	QUALITY_INBOUND_BITRATE = "inboundBitrate"
+	QUALITY_ROUND_TRIP_TIME = "roundTripTime"
+)
+
+// QualityThresholds are the limits of a good quality, a zero value disables
+// the check.
+type QualityThresholds struct {
+	MaxPacketLossPercent float64
+	MaxJitter            float64
+	MinInboundBitrate    float64
+	MaxRoundTripTime     float64
+}
+
+// QualityAlert is given to the hooks of a QualityMonitor when a threshold is
+// crossed.
+type QualityAlert struct {
+	// One of QUALITY_PACKET_LOSS, QUALITY_JITTER, QUALITY_INBOUND_BITRATE or
+	// QUALITY_ROUND_TRIP_TIME
+	Metric string
+
+	// Value of the metric and the threshold crossed
+	Value     float64
+	Threshold float64
+
+	// Sample that crossed the threshold
+	Sample QualitySample
+}
+
+// QualitySink receives every sample computed by a QualityMonitor, e.g. to
+// feed a dashboard.
+type QualitySink interface {
+	AddSample(QualitySample)
+}
+
+// Number of samples kept by element when QualityMonitor.HistorySize is not
+// set
+const defaultQualityHistory = 60
+
+// QualityMonitor polls the stats of elements at a regular interval and
+// computes their media quality. Create it with NewQualityMonitor, set hooks,
+// then call Run.
+type QualityMonitor struct {
+	// Time between two polls
+	Interval time.Duration
+
+	// Media type of stats, all types if empty
+	MediaType MediaType
+
+	// Limits that trigger OnAlert and OnRecover
+	Thresholds QualityThresholds
+
+	// Called when a metric crosses its threshold
+	OnAlert func(QualityAlert)
+
+	// Called when a metric goes back under its threshold
+	OnRecover func(QualityAlert)
+
+	// Called when stats can't be read
+	OnError func(element StatsGetter, err error)
+
+	// Receive all samples
+	Sinks []QualitySink
+
+	// Number of samples kept by element, see History
+	HistorySize int
+
+	mu       sync.Mutex
+	elements map[string]*monitoredElement
+}
+
+// State of an element between polls
+type monitoredElement struct {
+	element  StatsGetter
+	last     *qualityTotals
+	history  []QualitySample
+	alerting map[string]bool
+}
+
+// Counters read from stats at one poll
+type qualityTotals struct {
+	time            time.Time
+	bytesReceived   int64
+	bytesSent       int64
+	packetsReceived int64
+	packetsLost     int64
+	jitter          float64
+	roundTripTime   float64
+}
+
+// NewQualityMonitor returns a monitor that polls every interval.
+func NewQualityMonitor(interval time.Duration, thresholds QualityThresholds) *QualityMonitor {
+	return &QualityMonitor{
+		Interval:   interval,
+		Thresholds: thresholds,
+		elements:   make(map[string]*monitoredElement),
+	}
+}
+
+// Add starts monitoring an element.
+func (m *QualityMonitor) Add(element StatsGetter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.elements == nil {
+		m.elements = make(map[string]*monitoredElement)
+	}
+	m.elements[element.String()] = &monitoredElement{
+		element:  element,
+		alerting: make(map[string]bool),
+	}
+}
+
+// Remove stops monitoring an element and forgets its samples.
+func (m *QualityMonitor) Remove(element StatsGetter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.elements, element.String())
+}
+
+// History returns the last samples of an element, oldest first.
+func (m *QualityMonitor) History(element StatsGetter) []QualitySample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.elements[element.String()]; ok {
+		return append([]QualitySample{}, e.history...)
+	}
+	return nil
+}
+
+// Latest returns the last sample of each element that has one, by element
+// id.
+func (m *QualityMonitor) Latest() map[string]QualitySample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ret := make(map[string]QualitySample)
+	for id, e := range m.elements {
+		if len(e.history) > 0 {
+			ret[id] = e.history[len(e.history)-1]
+		}
+	}
+	return ret
+}
+
+// Run polls elements until ctx is done. The first sample of an element is
+// computed at its second poll. When the counters of an element go back, e.g.
+// when a stream gets a new SSRC, that poll gives no sample and the next one
+// starts from the new counters. It returns an error at once if Interval is not
+// positive.
+func (m *QualityMonitor) Run(ctx context.Context) error {
+	if m.Interval <= 0 {
+		return fmt.Errorf("kurento: invalid quality monitor interval %s", m.Interval)
+	}
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		m.Poll()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads stats of all elements once, and computes their samples.
+func (m *QualityMonitor) Poll() {
+	m.mu.Lock()
+	elements := make([]*monitoredElement, 0, len(m.elements))
+	for _, e := range m.elements {
+		elements = append(elements, e)
+	}
+	m.mu.Unlock()
+
+	for _, e := range elements {
+		stats, err := e.element.GetStats(m.MediaType)
+		if err != nil {
+			if m.OnError != nil {
+				m.OnError(e.element, err)
+			}
+			continue
+		}
+		m.update(e, sumStats(stats, time.Now()))
+	}
+}
+
+// Compute the sample of an element from the new totals
+func (m *QualityMonitor) update(e *monitoredElement, t *qualityTotals) {
+	m.mu.Lock()
+	last := e.last
+	e.last = t
+	if last == nil || t.before(last) {
+		// First poll, or counters went back because a stream was replaced
+		// (e.g. a new SSRC): there is no delta to compute until next poll
+		m.mu.Unlock()
+		return
+	}
+
+	s := QualitySample{
+		Element:       e.element.String(),
+		Time:          t.time,
+		Duration:      t.time.Sub(last.time),
+		Jitter:        t.jitter,
+		RoundTripTime: t.roundTripTime,
+	}
+	if secs := s.Duration.Seconds(); secs > 0 {
+		s.InboundBitrate = float64(t.bytesReceived-last.bytesReceived) * 8 / secs
+		s.OutboundBitrate = float64(t.bytesSent-last.bytesSent) * 8 / secs
+		s.JitterTrend = (t.jitter - last.jitter) / secs
+	}
+	lost := t.packetsLost - last.packetsLost
+	if total := t.packetsReceived - last.packetsReceived + lost; total > 0 && lost > 0 {
+		s.PacketLossPercent = float64(lost) * 100 / float64(total)
+	}
+
+	size := m.HistorySize
+	if size <= 0 {
+		size = defaultQualityHistory
+	}
+	e.history = append(e.history, s)
+	if len(e.history) > size {
+		e.history = e.history[len(e.history)-size:]
+	}
+
+	alerts, recovers := m.check(e, s)
+	m.mu.Unlock()
+
+	for _, sink := range m.Sinks {
+		sink.AddSample(s)
+	}
+	for _, a := range alerts {
+		if m.OnAlert != nil {
+			m.OnAlert(a)
+		}
+	}
+	for _, a := range recovers {
+		if m.OnRecover != nil {
+			m.OnRecover(a)
+		}
+	}
+}
+
+// Compare the sample to thresholds, return metrics that crossed them
+func (m *QualityMonitor) check(e *monitoredElement, s QualitySample) (alerts []QualityAlert, recovers []QualityAlert) {
+	th := m.Thresholds
+	checks := []struct {
+		metric    string
+		value     float64
+		threshold float64
+		bad       bool
+	}{
+		{QUALITY_PACKET_LOSS, s.PacketLossPercent, th.MaxPacketLossPercent, s.PacketLossPercent > th.MaxPacketLossPercent},
+		{QUALITY_JITTER, s.Jitter, th.MaxJitter, s.Jitter > th.MaxJitter},
+		{QUALITY_INBOUND_BITRATE, s.InboundBitrate, th.MinInboundBitrate, s.InboundBitrate < th.MinInboundBitrate},
+		{QUALITY_ROUND_TRIP_TIME, s.RoundTripTime, th.MaxRoundTripTime, s.RoundTripTime > th.MaxRoundTripTime},
+	}
+	for _, c := range checks {
+		if c.threshold == 0 {
+			continue
+		}
+		a := QualityAlert{c.metric, c.value, c.threshold, s}
+		switch {
+		case c.bad && !e.alerting[c.metric]:
+			e.alerting[c.metric] = true
+			alerts = append(alerts, a)
+		case !c.bad && e.alerting[c.metric]:
+			e.alerting[c.metric] = false
+			recovers = append(recovers, a)
+		}
+	}
+	return
+}
+
+// Tell if a counter is lower than in the previous totals
+func (t *qualityTotals) before(last *qualityTotals) bool {
+	return t.bytesReceived < last.bytesReceived ||
+		t.bytesSent < last.bytesSent ||
+		t.packetsReceived < last.packetsReceived ||
+		t.packetsLost < last.packetsLost
+}
+
+// Sum counters of all streams of a stats report
+func sumStats(stats map[string]Stats, now time.Time) *qualityTotals {
+	t := &qualityTotals{time: now}
+	for _, s := range stats {
+		switch s := s.(type) {
+		case *RTCInboundRTPStreamStats:
+			t.bytesReceived += s.BytesReceived
+			t.packetsReceived += s.PacketsReceived
+			t.packetsLost += s.PacketsLost
+			if s.Jitter > t.jitter {
+				t.jitter = s.Jitter
+			}
+		case *RTCOutboundRTPStreamStats:
+			t.bytesSent += s.BytesSent
+			if s.RoundTripTime > t.roundTripTime {
+				t.roundTripTime = s.RoundTripTime
+			}
+		case *RTCIceCandidatePairStats:
+			if s.RoundTripTime > t.roundTripTime {
+				t.roundTripTime = s.RoundTripTime
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,183 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQualityMonitorRunInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		m := NewQualityMonitor(interval, QualityThresholds{})
+		if err := m.Run(context.Background()); err == nil {
+			t.Errorf("Run with interval %s should fail", interval)
+		}
+	}
+}
+
+// Counters of one poll of a fakeStatsGetter
+type statsSnapshot struct {
+	bytesReceived   int64
+	packetsReceived int64
+	packetsLost     int64
+	jitter          float64
+	bytesSent       int64
+}
+
+type fakeStatsGetter struct {
+	snapshots []statsSnapshot
+	n         int
+}
+
+func (f *fakeStatsGetter) String() string { return "obj1" }
+
+func (f *fakeStatsGetter) GetStats(mediaType MediaType) (map[string]Stats, error) {
+	s := f.snapshots[f.n]
+	f.n++
+	in := &RTCInboundRTPStreamStats{}
+	in.BytesReceived = s.bytesReceived
+	in.PacketsReceived = s.packetsReceived
+	in.PacketsLost = s.packetsLost
+	in.Jitter = s.jitter
+	out := &RTCOutboundRTPStreamStats{}
+	out.BytesSent = s.bytesSent
+	return map[string]Stats{"in": in, "out": out}, nil
+}
+
+type qualitySinkFunc func(QualitySample)
+
+func (f qualitySinkFunc) AddSample(s QualitySample) { f(s) }
+
+func TestQualityMonitorPoll(t *testing.T) {
+	// Expected sample, bitrates and jitter trend are given as deltas since
+	// the previous poll as the duration between polls is not known
+	type sample struct {
+		bytesReceived int64
+		bytesSent     int64
+		loss          float64
+		jitter        float64
+		jitterDelta   float64
+	}
+	tests := []struct {
+		name       string
+		thresholds QualityThresholds
+		snapshots  []statsSnapshot
+		samples    []sample
+		hooks      []string
+	}{
+		{
+			name:       "bitrate and loss",
+			thresholds: QualityThresholds{MaxPacketLossPercent: 5},
+			snapshots: []statsSnapshot{
+				{0, 0, 0, 0.01, 0},
+				{125000, 90, 10, 0.02, 50000},
+				{250000, 190, 10, 0.015, 100000},
+			},
+			samples: []sample{
+				{125000, 50000, 10, 0.02, 0.01},
+				{125000, 50000, 0, 0.015, -0.005},
+			},
+			hooks: []string{"alert packetLoss 10", "recover packetLoss 0"},
+		},
+		{
+			name:       "alert once until recovered",
+			thresholds: QualityThresholds{MaxPacketLossPercent: 5, MaxJitter: 0.03},
+			snapshots: []statsSnapshot{
+				{0, 0, 0, 0.01, 0},
+				{1000, 90, 10, 0.04, 0},
+				{2000, 160, 30, 0.05, 0},
+				{3000, 260, 30, 0.02, 0},
+			},
+			samples: []sample{
+				{1000, 0, 10, 0.04, 0.03},
+				{1000, 0, 22.22222222222222, 0.05, 0.01},
+				{1000, 0, 0, 0.02, -0.03},
+			},
+			hooks: []string{
+				"alert packetLoss 10", "alert jitter 0.04",
+				"recover packetLoss 0", "recover jitter 0.02",
+			},
+		},
+		{
+			name:       "counter reset",
+			thresholds: QualityThresholds{MaxPacketLossPercent: 5},
+			snapshots: []statsSnapshot{
+				{0, 0, 0, 0.01, 0},
+				{125000, 90, 10, 0.01, 0},
+				{1000, 10, 0, 0.01, 0},
+				{126000, 110, 0, 0.01, 0},
+			},
+			samples: []sample{
+				{125000, 0, 10, 0.01, 0},
+				{125000, 0, 0, 0.01, 0},
+			},
+			hooks: []string{"alert packetLoss 10", "recover packetLoss 0"},
+		},
+		{
+			name: "no thresholds",
+			snapshots: []statsSnapshot{
+				{0, 0, 0, 0.01, 0},
+				{1000, 50, 50, 0.5, 0},
+			},
+			samples: []sample{
+				{1000, 0, 50, 0.5, 0.49},
+			},
+		},
+	}
+	near := func(a, b float64) bool {
+		d := a - b
+		return d < 1e-9 && d > -1e-9
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewQualityMonitor(time.Second, tt.thresholds)
+			var hooks []string
+			m.OnAlert = func(a QualityAlert) {
+				hooks = append(hooks, fmt.Sprintf("alert %s %v", a.Metric, a.Value))
+			}
+			m.OnRecover = func(a QualityAlert) {
+				hooks = append(hooks, fmt.Sprintf("recover %s %v", a.Metric, a.Value))
+			}
+			var samples []QualitySample
+			m.Sinks = []QualitySink{qualitySinkFunc(func(s QualitySample) {
+				samples = append(samples, s)
+			})}
+			e := &fakeStatsGetter{snapshots: tt.snapshots}
+			m.Add(e)
+			for range tt.snapshots {
+				m.Poll()
+				time.Sleep(time.Millisecond)
+			}
+
+			if len(samples) != len(tt.samples) {
+				t.Fatalf("%d samples, want %d", len(samples), len(tt.samples))
+			}
+			for i, want := range tt.samples {
+				s := samples[i]
+				secs := s.Duration.Seconds()
+				if s.Element != "obj1" || secs <= 0 {
+					t.Fatalf("sample %d is %+v", i, s)
+				}
+				if !near(s.InboundBitrate*secs, float64(want.bytesReceived*8)) ||
+					!near(s.OutboundBitrate*secs, float64(want.bytesSent*8)) {
+					t.Errorf("sample %d bitrates are %v/%v in %s, want %d/%d bytes",
+						i, s.InboundBitrate, s.OutboundBitrate, s.Duration, want.bytesReceived, want.bytesSent)
+				}
+				if !near(s.PacketLossPercent, want.loss) {
+					t.Errorf("sample %d loss is %v, want %v", i, s.PacketLossPercent, want.loss)
+				}
+				if s.Jitter != want.jitter || !near(s.JitterTrend*secs, want.jitterDelta) {
+					t.Errorf("sample %d jitter is %v trend %v in %s, want %v delta %v",
+						i, s.Jitter, s.JitterTrend, s.Duration, want.jitter, want.jitterDelta)
+				}
+			}
+			if fmt.Sprint(hooks) != fmt.Sprint(tt.hooks) {
+				t.Errorf("hooks are %q, want %q", hooks, tt.hooks)
+			}
+			if h := m.History(e); len(h) != len(samples) || h[len(h)-1] != samples[len(samples)-1] {
+				t.Errorf("history is %+v, want %+v", h, samples)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package kurento
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CustomConstructor can be implemented by types registered with RegisterType
+// to give their constructor params. Without it, "mediaPipeline" is set to the
+// object calling "Create", then options are merged.
+type CustomConstructor interface {
+	ConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{}
+}
+
+var (
+	typesMu sync.RWMutex
+
+	// Factories by KMS type name, used by Describe
+	typeFactories = map[string]func() IMediaObject{
+		"ServerManager":         func() IMediaObject { return &ServerManager{} },
+		"MediaPipeline":         func() IMediaObject { return &MediaPipeline{} },
+		"PassThrough":           func() IMediaObject { return &PassThrough{} },
+		"HubPort":               func() IMediaObject { return &HubPort{} },
+		"WebRtcEndpoint":        func() IMediaObject { return &WebRtcEndpoint{} },
+		"RtpEndpoint":           func() IMediaObject { return &RtpEndpoint{} },
+		"HttpGetEndpoint":       func() IMediaObject { return &HttpGetEndpoint{} },
+		"HttpPostEndpoint":      func() IMediaObject { return &HttpPostEndpoint{} },
+		"PlayerEndpoint":        func() IMediaObject { return &PlayerEndpoint{} },
+		"RecorderEndpoint":      func() IMediaObject { return &RecorderEndpoint{} },
+		"Composite":             func() IMediaObject { return &Composite{} },
+		"Mixer":                 func() IMediaObject { return &Mixer{} },
+		"AlphaBlending":         func() IMediaObject { return &AlphaBlending{} },
+		"Dispatcher":            func() IMediaObject { return &Dispatcher{} },
+		"DispatcherOneToMany":   func() IMediaObject { return &DispatcherOneToMany{} },
+		"FaceOverlayFilter":     func() IMediaObject { return &FaceOverlayFilter{} },
+		"ZBarFilter":            func() IMediaObject { return &ZBarFilter{} },
+		"GStreamerFilter":       func() IMediaObject { return &GStreamerFilter{} },
+		"ImageOverlayFilter":    func() IMediaObject { return &ImageOverlayFilter{} },
+		"ChromaFilter":          func() IMediaObject { return &ChromaFilter{} },
+		"CrowdDetectorFilter":   func() IMediaObject { return &CrowdDetectorFilter{} },
+		"PlateDetectorFilter":   func() IMediaObject { return &PlateDetectorFilter{} },
+		"PointerDetectorFilter": func() IMediaObject { return &PointerDetectorFilter{} },
+	}
+
+	// KMS type names of types registered with RegisterType
+	typeNames = map[reflect.Type]string{}
+)
+
+// RegisterType makes an element of a custom KMS module usable by the package.
+// kmsName is the type known by the media server, e.g. "myplugin.MyFilter", and
+// factory returns a new Go object for it. The Go type must embed one of the
+// package types, e.g. kurento.Filter, and may implement CustomConstructor.
+// Registered types can be created with "Create", returned by Describe, and
+// receive events registered with RegisterEvent.
+func RegisterType(kmsName string, factory func() IMediaObject) {
+	if factory == nil {
+		panic("kurento: RegisterType factory is nil for " + kmsName)
+	}
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	typeFactories[kmsName] = factory
+	typeNames[reflect.TypeOf(factory())] = kmsName
+}
+
+// RegisterEvent makes events of a custom KMS module typed. factory returns a
+// new event, that must embed kurento.MediaEvent. The event is decoded from
+// JSON with encoding/json.
+func RegisterEvent(kmsName string, factory func() Event) {
+	if factory == nil {
+		panic("kurento: RegisterEvent factory is nil for " + kmsName)
+	}
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	eventTypes[kmsName] = factory
+}
+
+// Return the KMS name of a registered type, false if it is not registered
+func registeredTypeName(m interface{}) (string, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	name, ok := typeNames[reflect.TypeOf(m)]
+	return name, ok
+}
+
+// Return a new event for the given event type, nil if it is not known
+func newEvent(eventType string) Event {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	if f, ok := eventTypes[eventType]; ok {
+		return f()
+	}
+	return nil
+}
+
+// Describe returns the object with the given id, typed after the type given
+// by the server. Types of custom modules must be registered with
+// RegisterType.
+func (c *Connection) Describe(id string) (IMediaObject, error) {
+	req := (&MediaObject{}).getCreateRequest()
+	req["method"] = "describe"
+	req["params"] = map[string]interface{}{
+		"object": id,
+	}
+
+	// Call server and wait response
+	response := <-c.Request(req)
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	typesMu.RLock()
+	factory, ok := typeFactories[response.Result["qualifiedType"]]
+	if !ok {
+		factory, ok = typeFactories[response.Result["type"]]
+	}
+	typesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("kurento: unknown type %q for %s, it must be registered with RegisterType", response.Result["type"], id)
+	}
+
+	m := factory()
+	m.setConnection(c)
+	m.setId(id)
+	return m, nil
+}
+
+// Invoke calls an operation of the object on the media server. It allows
+// types defined outside of the package to implement their methods.
+func (elem *MediaObject) Invoke(operation string, params map[string]interface{}) (Response, error) {
+	req := elem.getInvokeRequest()
+
+	p := map[string]interface{}{
+		"operation": operation,
+		"object":    elem.Id,
+	}
+	if len(params) > 0 {
+		p["operationParams"] = params
+	}
+	req["params"] = p
+
+	// Call server and wait response
+	response := <-elem.connection.Request(req)
+
+	return response, response.Err()
+}
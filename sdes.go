@@ -0,0 +1,63 @@
+package kurento
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Length of master key and salt by crypto suite
+var sdesKeyLength = map[CryptoSuite]int{
+	CRYPTOSUITE_AES_128_CM_HMAC_SHA1_32: 30,
+	CRYPTOSUITE_AES_128_CM_HMAC_SHA1_80: 30,
+	CRYPTOSUITE_AES_256_CM_HMAC_SHA1_32: 46,
+	CRYPTOSUITE_AES_256_CM_HMAC_SHA1_80: 46,
+}
+
+// NewSDES returns an SRTP configuration with a random key, generated with
+// crypto/rand.
+func NewSDES(suite CryptoSuite) (SDES, error) {
+	length, ok := sdesKeyLength[suite]
+	if !ok {
+		return SDES{}, fmt.Errorf("kurento: unknown crypto suite %q", suite)
+	}
+
+	key := make([]byte, length)
+	if _, err := rand.Read(key); err != nil {
+		return SDES{}, err
+	}
+
+	return SDES{
+		KeyBase64:   base64.StdEncoding.EncodeToString(key),
+		CryptoSuite: suite,
+	}, nil
+}
+
+// Validate checks the crypto suite and the key length.
+func (s SDES) Validate() error {
+	length, ok := sdesKeyLength[s.CryptoSuite]
+	if !ok {
+		return fmt.Errorf("kurento: unknown crypto suite %q", s.CryptoSuite)
+	}
+
+	switch {
+	case s.Key != "" && s.KeyBase64 != "":
+		return errors.New("kurento: SDES Key and KeyBase64 can't be both set")
+	case s.Key != "":
+		if len(s.Key) != length {
+			return fmt.Errorf("kurento: SDES key must be %d characters for %s, got %d", length, s.CryptoSuite, len(s.Key))
+		}
+	case s.KeyBase64 != "":
+		key, err := base64.StdEncoding.DecodeString(s.KeyBase64)
+		if err != nil {
+			return fmt.Errorf("kurento: SDES KeyBase64 is not valid base64: %v", err)
+		}
+		if len(key) != length {
+			return fmt.Errorf("kurento: SDES key must be %d bytes for %s, got %d", length, s.CryptoSuite, len(key))
+		}
+	default:
+		return errors.New("kurento: SDES needs a key")
+	}
+	return nil
+}
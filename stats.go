@@ -0,0 +1,299 @@
+package kurento
+
+import "encoding/json"
+
+// The type of the object.
+type StatsType string
+
+// Implement fmt.Stringer interface
+func (t StatsType) String() string {
+	return string(t)
+}
+
+const (
+	STATSTYPE_INBOUNDRTP      StatsType = "inboundrtp"
+	STATSTYPE_OUTBOUNDRTP     StatsType = "outboundrtp"
+	STATSTYPE_SESSION         StatsType = "session"
+	STATSTYPE_DATACHANNEL     StatsType = "datachannel"
+	STATSTYPE_TRACK           StatsType = "track"
+	STATSTYPE_TRANSPORT       StatsType = "transport"
+	STATSTYPE_CANDIDATEPAIR   StatsType = "candidatepair"
+	STATSTYPE_LOCALCANDIDATE  StatsType = "localcandidate"
+	STATSTYPE_REMOTECANDIDATE StatsType = "remotecandidate"
+	STATSTYPE_ELEMENT         StatsType = "element"
+	STATSTYPE_ENDPOINT        StatsType = "endpoint"
+)
+
+// Stats is implemented by all statistics returned by GetStats. The concrete
+// type depends on the stats type, e.g. *RTCInboundRTPStreamStats, or
+// *BaseStats if the type is not known by the package.
+type Stats interface {
+	getBaseStats() *BaseStats
+}
+
+// A dictionary that represents the stats gathered.
+type BaseStats struct {
+	// A unique id that is associated with the object that was inspected to
+	// produce this Stats object.
+	Id string
+
+	// The type of this object.
+	Type StatsType
+
+	// The timestamp associated with this object. The time is relative to the
+	// UNIX epoch (Jan 1, 1970, UTC), in seconds.
+	Timestamp float64
+
+	// The timestamp associated with this object, in milliseconds.
+	TimestampMillis int64
+}
+
+// Implement Stats interface
+func (s *BaseStats) getBaseStats() *BaseStats {
+	return s
+}
+
+// A latency measure of a media type
+type MediaLatencyStat struct {
+	// The identifier of the media stream
+	Name string
+
+	// Type of media
+	Type MediaType
+
+	// The average time that buffers take to get on the input pad of this
+	// element, in nanoseconds
+	Avg float64
+}
+
+// A dictionary that represents the stats gathered in the media element.
+type ElementStats struct {
+	BaseStats
+
+	// Average latency, in nanoseconds.
+	InputAudioLatency float64
+
+	// Average latency, in nanoseconds.
+	InputVideoLatency float64
+
+	// The average time that buffers take to get on the input pads of this
+	// element
+	InputLatency []MediaLatencyStat
+}
+
+// A dictionary that represents the stats gathered in the endpoint element.
+type EndpointStats struct {
+	ElementStats
+
+	// End-to-end audio latency measured in nano seconds
+	AudioE2ELatency float64
+
+	// End-to-end video latency measured in nano seconds
+	VideoE2ELatency float64
+
+	// The average end to end latency for each media stream, in nanoseconds
+	E2ELatency []MediaLatencyStat
+}
+
+// Statistics for the RTP stream
+type RTCRTPStreamStats struct {
+	BaseStats
+
+	// The synchronized source SSRC
+	Ssrc string
+
+	// The associateStatsId is used for looking up the corresponding (local/remote)
+	// RTCStats object for a given SSRC.
+	AssociateStatsId string
+
+	// false indicates that the statistics are measured locally, while true
+	// indicates that the measurements were done at the remote endpoint and
+	// reported in an RTCP RR/XR.
+	IsRemote bool
+
+	// Track identifier.
+	MediaTrackId string
+
+	// It is a unique identifier that is associated to the object that was
+	// inspected to produce the RTCTransportStats associated with this RTP
+	// stream.
+	TransportId string
+
+	// The codec identifier
+	CodecId string
+
+	// Count the total number of Full Intra Request (FIR) packets received by
+	// the sender.
+	FirCount int64
+
+	// Count the total number of Packet Loss Indication (PLI) packets received
+	// by the sender and is sent by receiver.
+	PliCount int64
+
+	// Count the total number of Negative ACKnowledgement (NACK) packets
+	// received by the sender and is sent by receiver.
+	NackCount int64
+
+	// Count the total number of Slice Loss Indication (SLI) packets received
+	// by the sender.
+	SliCount int64
+
+	// The Receiver Estimated Maximum Bitrate (REMB), in bps.
+	Remb int64
+
+	// Total number of RTP packets lost for this SSRC.
+	PacketsLost int64
+
+	// The fraction packet loss reported for this SSRC.
+	FractionLost float64
+}
+
+// Statistics that represents the measurement metrics for the incoming media
+// stream.
+type RTCInboundRTPStreamStats struct {
+	RTCRTPStreamStats
+
+	// Total number of RTP packets received for this SSRC.
+	PacketsReceived int64
+
+	// Total number of bytes received for this SSRC.
+	BytesReceived int64
+
+	// Packet Jitter measured in seconds for this SSRC.
+	Jitter float64
+}
+
+// Statistics that represents the measurement metrics for the outgoing media
+// stream.
+type RTCOutboundRTPStreamStats struct {
+	RTCRTPStreamStats
+
+	// Total number of RTP packets sent for this SSRC.
+	PacketsSent int64
+
+	// Total number of bytes sent for this SSRC.
+	BytesSent int64
+
+	// Presently configured bitrate target of this SSRC, in bits per second.
+	TargetBitrate float64
+
+	// Estimated round trip time (seconds) for this SSRC based on the RTCP
+	// timestamp.
+	RoundTripTime float64
+}
+
+// Statistics of an ICE candidate pair
+type RTCIceCandidatePairStats struct {
+	BaseStats
+
+	// It is a unique identifier that is associated to the object that was
+	// inspected to produce the RTCTransportStats associated with this candidates
+	// pair.
+	TransportId string
+
+	// It is a unique identifier that is associated to the object that was
+	// inspected to produce the RTCIceCandidateAttributes for the local
+	// candidate associated with this candidates pair.
+	LocalCandidateId string
+
+	// It is a unique identifier that is associated to the object that was
+	// inspected to produce the RTCIceCandidateAttributes for the remote
+	// candidate associated with this candidates pair.
+	RemoteCandidateId string
+
+	// Represents the state of the checklist for the local and remote candidates
+	// in a pair.
+	State string
+
+	// Calculated from candidate priorities as defined in [RFC5245] section
+	// 5.7.2.
+	Priority int64
+
+	// Related to updating the nominated flag described in Section 7.1.3.2.4 of
+	// [RFC5245].
+	Nominated bool
+
+	// Has gotten ACK to an ICE request.
+	Writable bool
+
+	// Has gotten a valid incoming ICE request.
+	Readable bool
+
+	// Represents the total number of payload bytes sent on this candidate pair.
+	BytesSent int64
+
+	// Represents the total number of payload bytes received on this candidate
+	// pair.
+	BytesReceived int64
+
+	// Represents the RTT computed by the STUN connectivity checks
+	RoundTripTime float64
+
+	// Measured in Bits per second, and is implementation dependent. It may be
+	// calculated by the underlying congestion control.
+	AvailableOutgoingBitrate float64
+
+	// Measured in Bits per second, and is implementation dependent. It may be
+	// calculated by the underlying congestion control.
+	AvailableIncomingBitrate float64
+}
+
+// Statistics related to RTC data channels.
+type RTCTransportStats struct {
+	BaseStats
+
+	// Represents the total number of payload bytes sent on this PeerConnection.
+	BytesSent int64
+
+	// Represents the total number of bytes received on this PeerConnection.
+	BytesReceived int64
+
+	// If RTP and RTCP are not multiplexed, this is the id of the transport
+	// that gives stats for the RTCP component.
+	RtcpTransportStatsId string
+
+	// Set to true when transport is active.
+	ActiveConnection bool
+
+	// It is a unique identifier that is associated to the object that was
+	// inspected to produce the RTCIceCandidatePairStats associated with this
+	// transport.
+	SelectedCandidatePairId string
+
+	// For components where DTLS is negotiated, give local certificate.
+	LocalCertificateId string
+
+	// For components where DTLS is negotiated, give remote certificate.
+	RemoteCertificateId string
+}
+
+// Stats structs by stats type
+var statsTypes = map[StatsType]func() Stats{
+	STATSTYPE_INBOUNDRTP:    func() Stats { return &RTCInboundRTPStreamStats{} },
+	STATSTYPE_OUTBOUNDRTP:   func() Stats { return &RTCOutboundRTPStreamStats{} },
+	STATSTYPE_CANDIDATEPAIR: func() Stats { return &RTCIceCandidatePairStats{} },
+	STATSTYPE_TRANSPORT:     func() Stats { return &RTCTransportStats{} },
+	STATSTYPE_ELEMENT:       func() Stats { return &ElementStats{} },
+	STATSTYPE_ENDPOINT:      func() Stats { return &EndpointStats{} },
+}
+
+// Build typed stats from the map returned by the server
+func decodeStats(raw map[string]json.RawMessage) map[string]Stats {
+	ret := make(map[string]Stats)
+	for id, data := range raw {
+		base := BaseStats{}
+		json.Unmarshal(data, &base)
+
+		var s Stats = &BaseStats{}
+		if f, ok := statsTypes[base.Type]; ok {
+			s = f()
+		}
+		// a field with an unexpected type is left empty, others are set
+		json.Unmarshal(data, s)
+		if s.getBaseStats().Id == "" {
+			s.getBaseStats().Id = id
+		}
+		ret[id] = s
+	}
+	return ret
+}
@@ -0,0 +1,191 @@
+package kurento
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directions of traced messages
+const (
+	TRACE_OUT = "out"
+	TRACE_IN  = "in"
+)
+
+// TraceEntry is one line written by a Tracer.
+type TraceEntry struct {
+	// Time when the message was sent or received
+	Time time.Time `json:"time"`
+
+	// TRACE_OUT for requests, TRACE_IN for responses and events
+	Direction string `json:"direction"`
+
+	// "request", "response" or "event"
+	Kind string `json:"kind"`
+
+	// JSON-RPC id, 0 for events
+	Id float64 `json:"id,omitempty"`
+
+	// JSON-RPC method of requests and events
+	Method string `json:"method,omitempty"`
+
+	// Object the message is about
+	Object string `json:"object,omitempty"`
+
+	// Time between the request and its response, in milliseconds
+	Latency float64 `json:"latencyMs,omitempty"`
+
+	// The message, with sensitive values redacted
+	Message interface{} `json:"message"`
+}
+
+// Tracer writes every message exchanged with the media server as JSON Lines,
+// see Connection.SetTracer. Passwords and keys are redacted.
+type Tracer struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	pending map[float64]tracedRequest
+}
+
+// Request waiting for its response
+type tracedRequest struct {
+	sent   time.Time
+	object string
+}
+
+// NewTracer returns a Tracer that appends entries to w.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{
+		enc:     json.NewEncoder(w),
+		pending: make(map[float64]tracedRequest),
+	}
+}
+
+// SetTracer starts tracing messages of the connection to t, or stops tracing
+// if t is nil.
+func (c *Connection) SetTracer(t *Tracer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracer = t
+}
+
+// Return the connection tracer, or nil
+func (c *Connection) getTracer() *Tracer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tracer
+}
+
+// Trace a request that is sent
+func (t *Tracer) request(req map[string]interface{}) {
+	msg := redact(toGeneric(req))
+	e := TraceEntry{
+		Time:      time.Now(),
+		Direction: TRACE_OUT,
+		Kind:      "request",
+		Message:   msg,
+	}
+	e.Id, _ = req["id"].(float64)
+	e.Method, _ = req["method"].(string)
+	e.Object = traceObject(msg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[e.Id] = tracedRequest{e.Time, e.Object}
+	t.enc.Encode(e)
+}
+
+// Trace a received message, response or event
+func (t *Tracer) receive(raw json.RawMessage) {
+	msg := redact(toGeneric(raw))
+	e := TraceEntry{
+		Time:      time.Now(),
+		Direction: TRACE_IN,
+		Kind:      "response",
+		Message:   msg,
+	}
+	if m, ok := msg.(map[string]interface{}); ok {
+		e.Id, _ = m["id"].(float64)
+		e.Method, _ = m["method"].(string)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e.Method != "" {
+		e.Kind = "event"
+		e.Object = traceObject(msg)
+	} else if r, ok := t.pending[e.Id]; ok {
+		delete(t.pending, e.Id)
+		e.Object = r.object
+		e.Latency = float64(e.Time.Sub(r.sent)) / float64(time.Millisecond)
+	}
+	t.enc.Encode(e)
+}
+
+// Find the object id in request or event params
+func traceObject(msg interface{}) string {
+	m, _ := msg.(map[string]interface{})
+	params, _ := m["params"].(map[string]interface{})
+	if o, ok := params["object"].(string); ok {
+		return o
+	}
+	value, _ := params["value"].(map[string]interface{})
+	o, _ := value["object"].(string)
+	return o
+}
+
+// Convert a message to maps, slices and basic types
+func toGeneric(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var ret interface{}
+	json.Unmarshal(b, &ret)
+	return ret
+}
+
+var (
+	// SDP attributes that hold secrets
+	sdpSecrets = regexp.MustCompile(`(a=ice-pwd:|inline:)[^\s|]+`)
+	// user:password@ in urls, with or without scheme. The user may contain
+	// colons, e.g. "timestamp:user" of TURN REST credentials, the password
+	// is what follows the last one.
+	urlPassword = regexp.MustCompile(`(^|://)([^\s/@]+):[^\s:@]+@`)
+)
+
+// Replace sensitive values in a generic message
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if s, ok := val.(string); ok && isSecretKey(key) {
+				if s != "" {
+					v[key] = "REDACTED"
+				}
+				continue
+			}
+			v[key] = redact(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val)
+		}
+	case string:
+		s := sdpSecrets.ReplaceAllString(v, "${1}REDACTED")
+		return urlPassword.ReplaceAllString(s, "${1}${2}:REDACTED@")
+	}
+	return v
+}
+
+// Check if a parameter name is known to hold a secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") ||
+		strings.Contains(key, "secret") ||
+		strings.HasPrefix(key, "key") ||
+		strings.HasSuffix(key, "key")
+}
@@ -0,0 +1,161 @@
+package kurento
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transports supported by TURN servers
+const (
+	TURN_UDP = "udp"
+	TURN_TCP = "tcp"
+	TURN_TLS = "tls"
+)
+
+// Default TURN server port
+const defaultTurnPort = 3478
+
+// TurnConfig describes a TURN server for a WebRtcEndpoint, see
+// WebRtcEndpoint.SetTurnConfig.
+type TurnConfig struct {
+	// Credentials on the TURN server
+	User     string
+	Password string
+
+	// IP address of the server, domain names are not supported by the media
+	// server
+	Host string
+
+	// Port of the server, 3478 if not set
+	Port int
+
+	// TURN_UDP, TURN_TCP or TURN_TLS. The media server chooses if not set.
+	Transport string
+}
+
+// Url returns the TURN URL in the format expected by the media server:
+// "user:password@host:port?transport=udp". An error is returned if the
+// configuration is not valid.
+func (t TurnConfig) Url() (string, error) {
+	// the media server splits user and password on the last ":", so user can
+	// hold an expiry as in "1700000000:alice", password can't
+	if t.User == "" || strings.ContainsAny(t.User, "@") {
+		return "", fmt.Errorf("kurento: invalid TURN user %q", t.User)
+	}
+	if t.Password == "" || strings.ContainsAny(t.Password, ":@") {
+		return "", fmt.Errorf("kurento: invalid TURN password")
+	}
+	if net.ParseIP(t.Host) == nil {
+		return "", fmt.Errorf("kurento: TURN host %q is not an IP address", t.Host)
+	}
+
+	port := t.Port
+	if port == 0 {
+		port = defaultTurnPort
+	}
+	if port < 0 || port > 65535 {
+		return "", fmt.Errorf("kurento: invalid TURN port %d", t.Port)
+	}
+
+	switch t.Transport {
+	case "", TURN_UDP, TURN_TCP, TURN_TLS:
+	default:
+		return "", fmt.Errorf("kurento: invalid TURN transport %q", t.Transport)
+	}
+
+	url := t.User + ":" + t.Password + "@" + net.JoinHostPort(t.Host, strconv.Itoa(port))
+	if t.Transport != "" {
+		url += "?transport=" + t.Transport
+	}
+	return url, nil
+}
+
+// RTCIceServer is the description of an ICE server for the RTCPeerConnection
+// configuration of a browser. It marshals to the JSON expected by the
+// "iceServers" member.
+type RTCIceServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// IceServer returns the TURN server description for the browser, with the
+// same credentials as the media server.
+func (t TurnConfig) IceServer() RTCIceServer {
+	port := t.Port
+	if port == 0 {
+		port = defaultTurnPort
+	}
+	url := "turn:" + net.JoinHostPort(t.Host, strconv.Itoa(port))
+	switch t.Transport {
+	case TURN_TLS:
+		url = "turns:" + net.JoinHostPort(t.Host, strconv.Itoa(port)) + "?transport=tcp"
+	case TURN_UDP, TURN_TCP:
+		url += "?transport=" + t.Transport
+	}
+	return RTCIceServer{
+		URLs:       []string{url},
+		Username:   t.User,
+		Credential: t.Password,
+	}
+}
+
+// TurnCredentials are short-lived credentials of the TURN REST API, as
+// supported by coturn with "use-auth-secret". The server checks them with the
+// secret it shares with the application.
+type TurnCredentials struct {
+	// "expiry:userId", expiry is a unix timestamp
+	Username string
+
+	// base64 encoded HMAC-SHA1 of Username, keyed with the shared secret
+	Password string
+
+	// Time after which the TURN server refuses the credentials
+	Expires time.Time
+}
+
+// NewTurnCredentials generates credentials for userId that are valid for ttl.
+// It returns an error if ttl is not positive, the credentials would already be
+// expired.
+func NewTurnCredentials(secret string, userId string, ttl time.Duration) (TurnCredentials, error) {
+	if ttl <= 0 {
+		return TurnCredentials{}, fmt.Errorf("kurento: invalid TURN credentials ttl %s", ttl)
+	}
+	return turnCredentials(secret, userId, time.Now().Add(ttl)), nil
+}
+
+// Generate credentials that expire at the given time
+func turnCredentials(secret string, userId string, expires time.Time) TurnCredentials {
+	username := strconv.FormatInt(expires.Unix(), 10)
+	if userId != "" {
+		username += ":" + userId
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(username))
+
+	return TurnCredentials{
+		Username: username,
+		Password: base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		Expires:  expires,
+	}
+}
+
+// TurnConfig returns the configuration of a TURN server using the
+// credentials. Give it to WebRtcEndpoint.SetTurnConfig, and its IceServer to
+// the browser.
+func (c TurnCredentials) TurnConfig(host string, port int, transport string) TurnConfig {
+	return TurnConfig{
+		User:      c.Username,
+		Password:  c.Password,
+		Host:      host,
+		Port:      port,
+		Transport: transport,
+	}
+}
@@ -0,0 +1,97 @@
+package kurento
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// Passwords are computed as in the coturn documentation of the TURN REST API:
+//
+//	echo -n "$username" | openssl dgst -binary -sha1 -hmac "$secret" | openssl base64
+func TestTurnCredentials(t *testing.T) {
+	expires := time.Unix(1433895918, 0)
+	tests := []struct {
+		userId   string
+		username string
+		password string
+	}{
+		{"alice", "1433895918:alice", "RqMvcGPYTJMGThVLSi4amT4zFtI="},
+		{"", "1433895918", "3dkl8uIBelQlraT8v9eCSJTyt+U="},
+	}
+	for _, tt := range tests {
+		c := turnCredentials("north", tt.userId, expires)
+		if c.Username != tt.username || c.Password != tt.password || !c.Expires.Equal(expires) {
+			t.Errorf("credentials of %q are %+v, want %s %s", tt.userId, c, tt.username, tt.password)
+		}
+	}
+}
+
+func TestNewTurnCredentials(t *testing.T) {
+	c, err := NewTurnCredentials("north", "alice", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(c.Expires); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("credentials expire in %s, want 1h", d)
+	}
+	if want := turnCredentials("north", "alice", c.Expires); c.Username != want.Username || c.Password != want.Password {
+		t.Errorf("credentials are %+v, want %+v", c, want)
+	}
+
+	for _, ttl := range []time.Duration{0, -time.Minute} {
+		if _, err := NewTurnCredentials("north", "alice", ttl); err == nil {
+			t.Errorf("NewTurnCredentials with ttl %s should fail", ttl)
+		}
+	}
+}
+
+func TestTurnConfigUrl(t *testing.T) {
+	tests := []struct {
+		config TurnConfig
+		want   string
+	}{
+		{TurnConfig{User: "1433895918:alice", Password: "RqMvcGPYTJMGThVLSi4amT4zFtI=", Host: "10.0.0.1"}, "1433895918:alice:RqMvcGPYTJMGThVLSi4amT4zFtI=@10.0.0.1:3478"},
+		{TurnConfig{User: "user", Password: "pwd", Host: "10.0.0.1", Port: 5349, Transport: TURN_TLS}, "user:pwd@10.0.0.1:5349?transport=tls"},
+		{TurnConfig{User: "user", Password: "pwd", Host: "::1", Transport: TURN_UDP}, "user:pwd@[::1]:3478?transport=udp"},
+		{TurnConfig{User: "", Password: "pwd", Host: "10.0.0.1"}, ""},
+		{TurnConfig{User: "user", Password: "p:wd", Host: "10.0.0.1"}, ""},
+		{TurnConfig{User: "user", Password: "pwd", Host: "turn.example.com"}, ""},
+		{TurnConfig{User: "user", Password: "pwd", Host: "10.0.0.1", Port: 70000}, ""},
+		{TurnConfig{User: "user", Password: "pwd", Host: "10.0.0.1", Transport: "sctp"}, ""},
+	}
+	for _, tt := range tests {
+		url, err := tt.config.Url()
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Url() of %+v should fail", tt.config)
+			}
+			continue
+		}
+		if err != nil || url != tt.want {
+			t.Errorf("Url() of %+v = %q, %v, want %q", tt.config, url, err, tt.want)
+		}
+	}
+}
+
+func TestTurnConfigIceServer(t *testing.T) {
+	tests := []struct {
+		config TurnConfig
+		want   string
+	}{
+		{
+			TurnConfig{User: "1433895918:alice", Password: "RqMvcGPYTJMGThVLSi4amT4zFtI=", Host: "10.0.0.1", Transport: TURN_UDP},
+			`{"urls":["turn:10.0.0.1:3478?transport=udp"],"username":"1433895918:alice","credential":"RqMvcGPYTJMGThVLSi4amT4zFtI="}`,
+		},
+		{
+			TurnConfig{User: "user", Password: "pwd", Host: "10.0.0.1", Port: 5349, Transport: TURN_TLS},
+			`{"urls":["turns:10.0.0.1:5349?transport=tcp"],"username":"user","credential":"pwd"}`,
+		},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.config.IceServer())
+		if err != nil || string(b) != tt.want {
+			t.Errorf("IceServer() of %+v is %s, %v, want %s", tt.config, b, err, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"golang.org/x/net/websocket"
 )
@@ -26,6 +27,43 @@ type Response struct {
 	Id      float64
 	Result  map[string]string // should change if result has no several form
 	Error   *Error
+
+	// "value" member of the result as sent by the server, for values that
+	// are not strings
+	value json.RawMessage
+}
+
+// Decode the "value" member of the result in v, or return the response error
+func (r Response) decodeValue(v interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if len(r.value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.value, v)
+}
+
+// notification is a message sent by the server without any request, such as
+// "onEvent"
+type notification struct {
+	Method string
+	Params struct {
+		Value struct {
+			Data   json.RawMessage
+			Object string
+			Type   string
+		}
+	}
+}
+
+// Err returns the response error, or nil if the request succeeded. Error field
+// can't be returned as is because a nil *Error is not a nil error.
+func (r Response) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+	return r.Error
 }
 
 type Connection struct {
@@ -34,6 +72,34 @@ type Connection struct {
 	host      string
 	ws        *websocket.Conn
 	SessionId string
+
+	// mu protects clients, clientId and subscriptions that are shared between
+	// callers and the reading goroutine
+	mu sync.Mutex
+
+	// event subscriptions by object and event type
+	subscriptions map[string]*subscription
+	handlerId     int
+
+	// events waiting to be given to handlers, the queue is not bounded so
+	// reading the websocket never waits for handlers
+	events      []notification
+	eventsReady chan struct{}
+
+	// channel subscribers, see Events
+	subscribers []*eventSubscriber
+
+	// see SetTracer
+	tracer *Tracer
+
+	// see ServerInfo
+	serverInfo *ServerInfo
+
+	// see SetAPIVersion
+	apiVersion APIVersion
+
+	// error of the API version detection, see APIVersion
+	apiVersionErr error
 }
 
 var connections = make(map[string]*Connection)
@@ -47,6 +113,8 @@ func NewConnection(host string) *Connection {
 	connections[host] = c
 
 	c.clients = make(map[float64]chan Response)
+	c.subscriptions = make(map[string]*subscription)
+	c.eventsReady = make(chan struct{}, 1)
 	var err error
 	c.ws, err = websocket.Dial(host+"/kurento", "", "http://127.0.0.1")
 	if err != nil {
@@ -54,19 +122,42 @@ func NewConnection(host string) *Connection {
 	}
 	c.host = host
 	go c.handleResponse()
+	go c.dispatchEvents()
 	return c
 }
 
-func (c *Connection) Create(m IMediaObject, options map[string]interface{}) {
+func (c *Connection) Create(m IMediaObject, options map[string]interface{}) error {
 	elem := &MediaObject{}
 	elem.setConnection(c)
-	elem.Create(m, options)
+	return elem.Create(m, options)
 }
 
 func (c *Connection) handleResponse() {
 	for { // run forever
+		var raw json.RawMessage
+		err := websocket.JSON.Receive(c.ws, &raw)
+		if t := c.getTracer(); t != nil && err == nil {
+			t.receive(raw)
+		}
+
+		n := notification{}
+		json.Unmarshal(raw, &n)
+		if n.Method == "onEvent" {
+			// handlers are called from another goroutine so they are able to
+			// send requests
+			c.queueEvent(n)
+			continue
+		}
+
 		r := Response{}
-		websocket.JSON.Receive(c.ws, &r)
+		json.Unmarshal(raw, &r)
+		result := struct {
+			Result struct {
+				Value json.RawMessage
+			}
+		}{}
+		json.Unmarshal(raw, &result)
+		r.value = result.Result.Value
 		if r.Result["sessionId"] != "" {
 			if debug {
 				log.Println("SESSIONID RETURNED")
@@ -74,10 +165,13 @@ func (c *Connection) handleResponse() {
 			c.SessionId = r.Result["sessionId"]
 		}
 		// if webscocket client exists, send response to the chanel
-		if c.clients[r.Id] != nil {
-			c.clients[r.Id] <- r
-			// chanel is read, we can delete it
-			delete(c.clients, r.Id)
+		c.mu.Lock()
+		client := c.clients[r.Id]
+		// chanel will be read, we can delete it
+		delete(c.clients, r.Id)
+		c.mu.Unlock()
+		if client != nil {
+			client <- r
 		} else if debug {
 			log.Println("Dropped message because there is no client ", r.Id)
 			log.Println(r)
@@ -87,16 +181,29 @@ func (c *Connection) handleResponse() {
 }
 
 func (c *Connection) Request(req map[string]interface{}) <-chan Response {
+	if err := c.checkRequest(req); err != nil {
+		client := make(chan Response, 1)
+		client <- Response{Jsonrpc: "2.0", Error: err}
+		return client
+	}
+
+	c.mu.Lock()
 	c.clientId++
 	req["id"] = c.clientId
-	if c.SessionId != "" {
-		req["sesionId"] = c.SessionId
+	if params, ok := req["params"].(map[string]interface{}); ok && c.SessionId != "" {
+		params["sessionId"] = c.SessionId
 	}
-	c.clients[c.clientId] = make(chan Response)
+	client := make(chan Response)
+	c.clients[c.clientId] = client
+	tracer := c.tracer
+	c.mu.Unlock()
 	if debug {
 		j, _ := json.MarshalIndent(req, "", "    ")
 		log.Println("json", string(j))
 	}
+	if tracer != nil {
+		tracer.request(req)
+	}
 	websocket.JSON.Send(c.ws, req)
-	return c.clients[c.clientId]
+	return client
 }